@@ -0,0 +1,107 @@
+// Package apperr classifies the errors handlers can run into so they can be
+// reported to users and API clients without leaking internal details.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind is the category of an error
+type Kind int
+
+const (
+	// Internal is a bug or server misconfiguration; details are never shown
+	Internal Kind = iota
+	// NotConfigured is a sheet, tab or other resource the site has no configuration for
+	NotConfigured
+	// NotFound is a card, plan, subscription or other record that doesn't
+	// exist, or no longer does
+	NotFound
+	// Unavailable is an upstream service such as Google Sheets failing
+	Unavailable
+	// BadInput is a malformed request
+	BadInput
+	// RateLimited is a client sending more requests than it is allowed to
+	RateLimited
+	// Unauthorized is a request that needs the client to sign in
+	Unauthorized
+	// Forbidden is a request the client is not allowed to make
+	Forbidden
+)
+
+// kindInfo holds how each kind is reported
+var kindInfo = map[Kind]struct {
+	Slug    string
+	Title   string
+	Status  int
+	Message string // default message shown to users
+}{
+	Internal:      {"internal", "Something went wrong", http.StatusInternalServerError, "An unexpected error occurred."},
+	NotConfigured: {"not-configured", "Not found", http.StatusNotFound, "This page isn't set up on mili.fit."},
+	NotFound:      {"not-found", "Not found", http.StatusNotFound, "This page doesn't exist."},
+	Unavailable:   {"upstream-unavailable", "Temporarily unavailable", http.StatusBadGateway, "The spreadsheet this data comes from can't be reached right now."},
+	BadInput:      {"bad-input", "Invalid request", http.StatusBadRequest, "The request was malformed."},
+	RateLimited:   {"rate-limited", "Too many requests", http.StatusTooManyRequests, "You're doing that too often. Please try again later."},
+	Unauthorized:  {"unauthorized", "Sign in required", http.StatusUnauthorized, "You need to sign in to see this page."},
+	Forbidden:     {"forbidden", "Not allowed", http.StatusForbidden, "You don't have access to this page."},
+}
+
+// Slug returns a short identifier of the kind, e.g. "not-configured"
+func (k Kind) Slug() string { return kindInfo[k].Slug }
+
+// Title returns a short human readable summary of the kind
+func (k Kind) Title() string { return kindInfo[k].Title }
+
+// Status returns the HTTP status code errors of the kind are reported with
+func (k Kind) Status() int { return kindInfo[k].Status }
+
+func (k Kind) String() string { return k.Slug() }
+
+// Error is an error of a known kind. Message is safe to show to users; the
+// wrapped Err is only logged.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// New returns an error of the given kind with a user-facing message
+func New(kind Kind, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap classifies err, keeping it for logs behind a user-facing message
+func Wrap(kind Kind, err error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// KindOf returns the kind of the first classified error in err's chain, or
+// Internal when there is none
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Internal
+}
+
+// Message returns the user-facing message of err. Internal errors get a
+// generic message so their details stay in the logs.
+func Message(err error) string {
+	var e *Error
+	if errors.As(err, &e) && e.Kind != Internal && e.Message != "" {
+		return e.Message
+	}
+	return kindInfo[KindOf(err)].Message
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"sync"
+
+	"disaster/store"
+)
+
+// addressKeyFile keeps the random key address keys are made with when
+// SESSION_SECRET is unset, so they still match after a restart
+const addressKeyFile = "address_key.json"
+
+var (
+	addressKeyOnce   sync.Once
+	addressKeySecret []byte
+)
+
+// addressSecret returns SESSION_SECRET, or a random key kept in the store
+func addressSecret() []byte {
+	addressKeyOnce.Do(func() {
+		if key := os.Getenv("SESSION_SECRET"); key != "" {
+			addressKeySecret = []byte(key)
+			return
+		}
+		var stored struct {
+			Key []byte `json:"key"`
+		}
+		if err := store.Load(addressKeyFile, &stored); err != nil {
+			log.Printf("Auth: error loading address key: %v", err)
+		}
+		if len(stored.Key) == 0 {
+			stored.Key = make([]byte, 32)
+			if _, err := rand.Read(stored.Key); err != nil {
+				panic(err)
+			}
+			if err := store.Save(addressKeyFile, stored); err != nil {
+				log.Printf("Auth: error saving address key: %v", err)
+			}
+		}
+		addressKeySecret = stored.Key
+	})
+	return addressKeySecret
+}
+
+// AddressKey identifies a visitor's address for a purpose, such as counting
+// their problem reports once, without storing the address. It is keyed with
+// a server secret, so the address can't be found again by hashing every
+// possible one.
+func AddressKey(purpose, addr string) string {
+	h := hmac.New(sha256.New, addressSecret())
+	h.Write([]byte("address\x00" + purpose + "\x00"))
+	h.Write([]byte(addr))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"disaster/apperr"
+)
+
+// MagicLinkLength is how long an emailed sign-in link works
+const MagicLinkLength = 15 * time.Minute
+
+// magicLink is the signed content of a sign-in link
+type magicLink struct {
+	Email   string    `json:"email"`
+	Expires time.Time `json:"expires"`
+	Nonce   string    `json:"nonce"`
+}
+
+var (
+	usedMu sync.Mutex
+	// used holds the nonces of redeemed links until they expire, so each
+	// link signs in once. It is kept in memory; a restart with the same
+	// SESSION_SECRET lets unexpired links be used again.
+	used = make(map[string]time.Time)
+)
+
+// MagicLinkToken returns the token of a sign-in link for email
+func MagicLinkToken(email string) (string, error) {
+	return sign("magic-link", magicLink{
+		Email:   strings.ToLower(email),
+		Expires: time.Now().Add(MagicLinkLength),
+		Nonce:   randomString(16),
+	})
+}
+
+// RedeemMagicLink returns the email address a sign-in link was sent to. Each
+// link works once, until it expires.
+func RedeemMagicLink(token string) (string, error) {
+	var link magicLink
+	if !verify("magic-link", token, &link) || time.Now().After(link.Expires) {
+		return "", apperr.New(apperr.Unauthorized, "This sign-in link has expired or was already used.")
+	}
+
+	usedMu.Lock()
+	defer usedMu.Unlock()
+	now := time.Now()
+	for nonce, expires := range used {
+		if now.After(expires) {
+			delete(used, nonce)
+		}
+	}
+	if _, ok := used[link.Nonce]; ok {
+		return "", apperr.New(apperr.Unauthorized, "This sign-in link has expired or was already used.")
+	}
+	used[link.Nonce] = link.Expires
+	return link.Email, nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedeemMagicLink(t *testing.T) {
+	token, err := MagicLinkToken("Visitor@Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	email, err := RedeemMagicLink(token)
+	if err != nil || email != "visitor@example.com" {
+		t.Fatalf("RedeemMagicLink = %q, %v, want visitor@example.com", email, err)
+	}
+	if _, err := RedeemMagicLink(token); err == nil {
+		t.Error("a sign-in link worked twice")
+	}
+
+	other, err := MagicLinkToken("visitor@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RedeemMagicLink(other); err != nil {
+		t.Errorf("a second link for the same address didn't work: %v", err)
+	}
+}
+
+func TestRedeemMagicLinkRejects(t *testing.T) {
+	expired, err := sign("magic-link", magicLink{Email: "visitor@example.com", Expires: time.Now().Add(-time.Minute), Nonce: randomString(16)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := sign("session", magicLink{Email: "visitor@example.com", Expires: time.Now().Add(time.Minute), Nonce: randomString(16)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{"expired": expired, "other purpose": session, "garbage": "not-a-token"} {
+		if email, err := RedeemMagicLink(token); err == nil {
+			t.Errorf("%s link signed in %q", name, email)
+		}
+	}
+}
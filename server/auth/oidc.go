@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"disaster/apperr"
+)
+
+// oidcCookie holds the state of a sign-in with the OIDC provider between the
+// redirect to the provider and the callback
+const oidcCookie = "oidc_login"
+
+// oidcLoginLength is how long a sign-in with the OIDC provider can take
+const oidcLoginLength = 10 * time.Minute
+
+// OIDCProvider signs users in with an OpenID Connect provider, using the
+// authorization code flow with PKCE. The email address is read from the
+// provider's userinfo endpoint, over the same TLS connection as the token, so
+// the ID token's signature doesn't need checking. Any issuer serving a
+// discovery document works, including a local mock issuer.
+type OIDCProvider struct {
+	Name         string // shown on the sign-in button
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Client       *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+}
+
+// oidcDiscovery is the part of the provider's discovery document in use
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// OIDCFromEnv returns the provider configured by OIDC_ISSUER, OIDC_CLIENT_ID,
+// OIDC_CLIENT_SECRET and OIDC_NAME, or nil when OIDC_ISSUER is unset
+func OIDCFromEnv() *OIDCProvider {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil
+	}
+	name := os.Getenv("OIDC_NAME")
+	if name == "" {
+		name = "single sign-on"
+	}
+	return &OIDCProvider{
+		Name:         name,
+		Issuer:       strings.TrimSuffix(issuer, "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// discover fetches the provider's discovery document once
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d oidcDiscovery
+	if err := p.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", "", &d); err != nil {
+		return nil, apperr.Wrap(apperr.Unavailable, err, "The sign-in provider is unavailable right now")
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != p.Issuer {
+		err := fmt.Errorf("discovery document is for issuer %q, not %q", d.Issuer, p.Issuer)
+		return nil, apperr.Wrap(apperr.Unavailable, err, "The sign-in provider is unavailable right now")
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.UserinfoEndpoint == "" {
+		err := fmt.Errorf("discovery document of %s lacks an authorization, token or userinfo endpoint", p.Issuer)
+		return nil, apperr.Wrap(apperr.Unavailable, err, "The sign-in provider is unavailable right now")
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// StartLogin remembers a new sign-in in a signed cookie and returns the
+// provider URL to send the browser to. After signing in the provider sends it
+// back to redirectURL.
+func (p *OIDCProvider) StartLogin(w http.ResponseWriter, r *http.Request, redirectURL, next string) (string, error) {
+	d, err := p.discover(r.Context())
+	if err != nil {
+		return "", err
+	}
+	login := oidcLogin{
+		State:    randomString(16),
+		Verifier: randomString(32),
+		Next:     next,
+		Expires:  time.Now().Add(oidcLoginLength),
+	}
+	value, err := sign(oidcCookie, login)
+	if err != nil {
+		return "", err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  login.Expires,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	challenge := sha256.Sum256([]byte(login.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectURL},
+		"scope":                 {"openid email"},
+		"state":                 {login.State},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return d.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// FinishLogin completes a sign-in when the provider sends the browser back to
+// redirectURL. It returns the user's verified email address and the page the
+// sign-in started from.
+func (p *OIDCProvider) FinishLogin(w http.ResponseWriter, r *http.Request, redirectURL string) (email, next string, err error) {
+	failed := func(err error) (string, string, error) {
+		return "", "", apperr.Wrap(apperr.Unauthorized, err, "Signing in didn't work. Please try again.")
+	}
+
+	var login oidcLogin
+	cookie, err := r.Cookie(oidcCookie)
+	if err != nil || !verify(oidcCookie, cookie.Value, &login) || time.Now().After(login.Expires) {
+		return failed(fmt.Errorf("no sign-in in progress"))
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcCookie, Path: "/", MaxAge: -1, HttpOnly: true, Secure: isHTTPS(r), SameSite: http.SameSiteLaxMode})
+
+	query := r.URL.Query()
+	if errorCode := query.Get("error"); errorCode != "" {
+		return failed(fmt.Errorf("provider returned %s: %s", errorCode, query.Get("error_description")))
+	}
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(login.State)) != 1 {
+		return failed(fmt.Errorf("state does not match"))
+	}
+
+	d, err := p.discover(r.Context())
+	if err != nil {
+		return "", "", err
+	}
+	accessToken, err := p.exchange(r.Context(), d, query.Get("code"), login.Verifier, redirectURL)
+	if err != nil {
+		return failed(err)
+	}
+	var info struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := p.getJSON(r.Context(), d.UserinfoEndpoint, accessToken, &info); err != nil {
+		return failed(fmt.Errorf("fetching userinfo: %w", err))
+	}
+	if info.Email == "" || !info.EmailVerified {
+		return failed(fmt.Errorf("provider gave no verified email address"))
+	}
+	return strings.ToLower(info.Email), login.Next, nil
+}
+
+// oidcLogin is the signed content of the sign-in cookie
+type oidcLogin struct {
+	State    string    `json:"state"`
+	Verifier string    `json:"verifier"`
+	Next     string    `json:"next"`
+	Expires  time.Time `json:"expires"`
+}
+
+// exchange trades an authorization code for an access token
+func (p *OIDCProvider) exchange(ctx context.Context, d *oidcDiscovery, code, verifier, redirectURL string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"code_verifier": {verifier},
+		"client_id":     {p.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := p.do(req, &token); err != nil {
+		return "", fmt.Errorf("exchanging code: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("exchanging code: no access token in response")
+	}
+	return token.AccessToken, nil
+}
+
+// getJSON fetches a JSON document, with a bearer token when one is given
+func (p *OIDCProvider) getJSON(ctx context.Context, endpoint, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return p.do(req, v)
+}
+
+// do sends a request to the provider and decodes its JSON response
+func (p *OIDCProvider) do(req *http.Request, v any) error {
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
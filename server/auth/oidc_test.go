@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testClientID     = "mili-fit"
+	testClientSecret = "s3cret"
+	testRedirectURL  = "https://mili.fit/login/oidc/callback"
+)
+
+// mockIssuer is an OIDC provider serving discovery, token and userinfo
+// endpoints. It hands out one code per sign-in, set with authorize.
+type mockIssuer struct {
+	*httptest.Server
+	email    string
+	verified bool
+
+	mu        sync.Mutex
+	challenge string // code challenge of the pending code
+	code      string
+}
+
+func newMockIssuer(t *testing.T, email string, verified bool) *mockIssuer {
+	t.Helper()
+	m := &mockIssuer{email: email, verified: verified}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"userinfo_endpoint":      m.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != testClientID || secret != testClientSecret {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		m.mu.Lock()
+		code, challenge := m.code, m.challenge
+		m.code = ""
+		m.mu.Unlock()
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if code == "" || r.PostFormValue("code") != code ||
+			r.PostFormValue("redirect_uri") != testRedirectURL ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "token-for-" + code, "token_type": "Bearer"})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token-for-") {
+			http.Error(w, "", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"sub": "1", "email": m.email, "email_verified": m.verified})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// authorize plays the user signing in at the provider: it checks the
+// authorization URL and returns the code it would send back
+func (m *mockIssuer) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != m.URL+"/authorize" {
+		t.Fatalf("authorization URL %s, want %s/authorize", got, m.URL)
+	}
+	q := u.Query()
+	if q.Get("client_id") != testClientID || q.Get("redirect_uri") != testRedirectURL || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected authorization query %v", q)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.code = "code-" + q.Get("state")
+	m.challenge = q.Get("code_challenge")
+	return m.code
+}
+
+func (m *mockIssuer) provider() *OIDCProvider {
+	return &OIDCProvider{
+		Name:         "Mock",
+		Issuer:       m.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		Client:       m.Client(),
+	}
+}
+
+// startLogin starts a sign-in and returns the sign-in cookie and the state and
+// code the provider sends back
+func startLogin(t *testing.T, m *mockIssuer, p *OIDCProvider, next string) (*http.Cookie, string, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	authURL, err := p.StartLogin(rec, httptest.NewRequest("GET", "/login/oidc", nil), testRedirectURL, next)
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcCookie || !cookies[0].HttpOnly {
+		t.Fatalf("StartLogin set cookies %v, want one HttpOnly %s", cookies, oidcCookie)
+	}
+	u, _ := url.Parse(authURL)
+	return cookies[0], u.Query().Get("state"), m.authorize(t, authURL)
+}
+
+// callback returns the request of the provider sending the browser back
+func callback(cookie *http.Cookie, query url.Values) *http.Request {
+	r := httptest.NewRequest("GET", "/login/oidc/callback?"+query.Encode(), nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+func TestOIDCLogin(t *testing.T) {
+	m := newMockIssuer(t, "Admin@Example.com", true)
+	p := m.provider()
+	cookie, state, code := startLogin(t, m, p, "/admin")
+
+	rec := httptest.NewRecorder()
+	email, next, err := p.FinishLogin(rec, callback(cookie, url.Values{"code": {code}, "state": {state}}), testRedirectURL)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if email != "admin@example.com" || next != "/admin" {
+		t.Errorf("FinishLogin = %q, %q, want admin@example.com, /admin", email, next)
+	}
+	if cookies := rec.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("FinishLogin didn't clear the sign-in cookie: %v", cookies)
+	}
+}
+
+func TestOIDCLoginFails(t *testing.T) {
+	tests := []struct {
+		name     string
+		verified bool
+		// request returns the callback from the cookie, state and code of a
+		// sign-in
+		request func(cookie *http.Cookie, state, code string) *http.Request
+	}{
+		{
+			name:     "state mismatch",
+			verified: true,
+			request: func(cookie *http.Cookie, state, code string) *http.Request {
+				return callback(cookie, url.Values{"code": {code}, "state": {state + "x"}})
+			},
+		},
+		{
+			name:     "no sign-in cookie",
+			verified: true,
+			request: func(cookie *http.Cookie, state, code string) *http.Request {
+				return callback(nil, url.Values{"code": {code}, "state": {state}})
+			},
+		},
+		{
+			name:     "tampered cookie",
+			verified: true,
+			request: func(cookie *http.Cookie, state, code string) *http.Request {
+				forged := *cookie
+				forged.Value = strings.Replace(cookie.Value, ".", ".A", 1)
+				return callback(&forged, url.Values{"code": {code}, "state": {state}})
+			},
+		},
+		{
+			name:     "wrong code",
+			verified: true,
+			request: func(cookie *http.Cookie, state, code string) *http.Request {
+				return callback(cookie, url.Values{"code": {"other"}, "state": {state}})
+			},
+		},
+		{
+			name:     "provider error",
+			verified: true,
+			request: func(cookie *http.Cookie, state, code string) *http.Request {
+				return callback(cookie, url.Values{"error": {"access_denied"}, "state": {state}})
+			},
+		},
+		{
+			name:     "unverified email",
+			verified: false,
+			request: func(cookie *http.Cookie, state, code string) *http.Request {
+				return callback(cookie, url.Values{"code": {code}, "state": {state}})
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := newMockIssuer(t, "admin@example.com", test.verified)
+			p := m.provider()
+			cookie, state, code := startLogin(t, m, p, "/admin")
+			email, _, err := p.FinishLogin(httptest.NewRecorder(), test.request(cookie, state, code), testRedirectURL)
+			if err == nil {
+				t.Errorf("FinishLogin signed in %q, want an error", email)
+			}
+		})
+	}
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	m := newMockIssuer(t, "admin@example.com", true)
+	p := m.provider()
+	p.Issuer = m.URL + "/other"
+	if _, err := p.StartLogin(httptest.NewRecorder(), httptest.NewRequest("GET", "/login/oidc", nil), testRedirectURL, "/"); err == nil {
+		t.Error("StartLogin accepted a discovery document for another issuer")
+	}
+}
@@ -0,0 +1,79 @@
+// Package auth signs in the people who run the site, by emailed magic link or
+// an optional OIDC provider, and keeps them signed in with signed session
+// cookies. What they may do is set by their role in AUTH_USERS.
+package auth
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// Role is what a signed-in user may do. Each role can do everything the
+// roles before it can.
+type Role int
+
+const (
+	// Viewer can see the admin pages
+	Viewer Role = iota + 1
+	// Editor can also review suggestions and reports
+	Editor
+	// Admin can also manage the running site, such as refreshing the snapshot
+	Admin
+)
+
+var roleNames = map[Role]string{
+	Viewer: "viewer",
+	Editor: "editor",
+	Admin:  "admin",
+}
+
+func (r Role) String() string { return roleNames[r] }
+
+// Allows reports whether the role includes the required one
+func (r Role) Allows(required Role) bool {
+	return r >= required
+}
+
+// ParseRole returns the role with the given name
+func ParseRole(name string) (Role, bool) {
+	for role, roleName := range roleNames {
+		if strings.EqualFold(name, roleName) {
+			return role, true
+		}
+	}
+	return 0, false
+}
+
+// RoleOf returns the role of an email address in AUTH_USERS, a comma separated
+// list such as "ann@example.org=admin,bob@example.org=editor". Addresses not
+// in the list can't sign in.
+func RoleOf(email string) (Role, bool) {
+	for _, entry := range strings.Split(os.Getenv("AUTH_USERS"), ",") {
+		address, name, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(address), email) {
+			continue
+		}
+		return ParseRole(strings.TrimSpace(name))
+	}
+	return 0, false
+}
+
+// User is a signed-in user
+type User struct {
+	Email string
+	Role  Role
+}
+
+type contextKey struct{}
+
+// WithUser returns a copy of ctx carrying the signed-in user
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFrom returns the signed-in user of ctx
+func UserFrom(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(contextKey{}).(User)
+	return user, ok
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SessionCookie is the name of the cookie holding the signed session
+const SessionCookie = "session"
+
+// SessionLength is how long a sign-in lasts
+const SessionLength = 7 * 24 * time.Hour
+
+// session is the signed content of the session cookie. The role isn't kept,
+// so changes to AUTH_USERS apply to existing sessions.
+type session struct {
+	Email   string    `json:"email"`
+	Expires time.Time `json:"expires"`
+}
+
+// StartSession sets the session cookie signing in email
+func StartSession(w http.ResponseWriter, r *http.Request, email string) error {
+	expires := time.Now().Add(SessionLength)
+	value, err := sign(SessionCookie, session{Email: email, Expires: expires})
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// EndSession clears the session cookie
+func EndSession(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// FromRequest returns the user signed in by the request's session cookie.
+// Sessions of addresses since taken out of AUTH_USERS are not valid.
+func FromRequest(r *http.Request) (User, bool) {
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return User{}, false
+	}
+	var s session
+	if !verify(SessionCookie, cookie.Value, &s) || time.Now().After(s.Expires) {
+		return User{}, false
+	}
+	role, ok := RoleOf(s.Email)
+	if !ok {
+		return User{}, false
+	}
+	return User{Email: s.Email, Role: role}, true
+}
+
+// isHTTPS reports whether the request reached the site over HTTPS, directly
+// or through a proxy
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
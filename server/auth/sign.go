@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	secretOnce sync.Once
+	secret     []byte
+)
+
+// signingKey returns SESSION_SECRET, or a random key when it is unset, which
+// signs everyone out whenever the server restarts
+func signingKey() []byte {
+	secretOnce.Do(func() {
+		if key := os.Getenv("SESSION_SECRET"); key != "" {
+			secret = []byte(key)
+			return
+		}
+		log.Printf("Auth: SESSION_SECRET is not set, sessions won't survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+	})
+	return secret
+}
+
+// mac signs payload for a purpose, so a value signed for one purpose, such as
+// a magic link, can't be passed off as another, such as a session
+func mac(purpose string, payload []byte) []byte {
+	h := hmac.New(sha256.New, signingKey())
+	h.Write([]byte(purpose))
+	h.Write([]byte{0})
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// sign encodes v as JSON and signs it for a purpose
+func sign(purpose string, v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(mac(purpose, payload)), nil
+}
+
+// verify decodes a value made by sign for the same purpose into v, and
+// reports whether its signature is valid
+func verify(purpose, token string, v any) bool {
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(got, mac(purpose, payload)) {
+		return false
+	}
+	return json.Unmarshal(payload, v) == nil
+}
+
+// randomString returns n random bytes, base64 encoded for URLs
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
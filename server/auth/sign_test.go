@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	type payload struct {
+		Email string `json:"email"`
+	}
+	token, err := sign("session", payload{Email: "admin@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got payload
+	if !verify("session", token, &got) || got.Email != "admin@example.com" {
+		t.Errorf("verify(%q) = %+v, want the signed payload", token, got)
+	}
+	if verify("magic-link", token, &payload{}) {
+		t.Error("a token signed for one purpose verified for another")
+	}
+
+	encodedPayload, encodedMAC, _ := strings.Cut(token, ".")
+	forged, err := sign("session", payload{Email: "attacker@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+	for _, bad := range []string{
+		"",
+		encodedPayload,
+		encodedPayload + ".",
+		forgedPayload + "." + encodedMAC,
+		encodedPayload + "." + encodedMAC + "A",
+		"!!!." + encodedMAC,
+	} {
+		if verify("session", bad, &payload{}) {
+			t.Errorf("verify(%q) accepted an invalid token", bad)
+		}
+	}
+}
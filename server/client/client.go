@@ -0,0 +1,95 @@
+// Package client is a typed Go client for the mili.fit v1 JSON API.
+//
+// The types and methods in client_gen.go are generated from the server's
+// OpenAPI document; run go generate after changing the API.
+package client
+
+//go:generate go run ../cmd/genclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the v1 API of a mili.fit server
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// New returns a client for the server at baseURL, e.g. "https://mili.fit"
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Error is a non-2xx response from the API. Problem holds the decoded
+// problem details when the server sent them.
+type Error struct {
+	StatusCode int
+	Message    string
+	Problem    *Problem
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api: %d %s", e.StatusCode, e.Message)
+}
+
+// DecodeFields decodes the fields of a row into the row type named by the
+// component of its tab, e.g. a DiscountRow for a DiscountCard tab
+func (r Row) DecodeFields(v any) error {
+	return json.Unmarshal(r.Fields, v)
+}
+
+// get performs a GET request and returns the response body
+func (c *Client) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+		var problem Problem
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/problem+json") && json.Unmarshal(body, &problem) == nil {
+			apiErr.Problem = &problem
+			apiErr.Message = problem.Title
+			if problem.Detail != "" {
+				apiErr.Message = problem.Detail
+			}
+		}
+		return nil, apiErr
+	}
+	return body, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into out
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	body, err := c.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
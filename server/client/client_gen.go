@@ -0,0 +1,187 @@
+// Code generated by genclient from the OpenAPI document. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+var (
+	_ = json.RawMessage(nil)
+	_ = time.Time{}
+)
+
+// Category is the Category schema of the API
+type Category struct {
+	Name string `json:"name"`
+}
+
+// CompanyField is the CompanyField schema of the API
+type CompanyField struct {
+	Text string `json:"text"`
+	Link string `json:"link,omitempty"`
+}
+
+// DiscountRow is the DiscountRow schema of the API
+type DiscountRow struct {
+	// Sheet column "Date Added"
+	DateAdded time.Time `json:"dateAdded,omitzero"`
+	// Sheet column "Company"
+	Company CompanyField `json:"company"`
+	// Sheet column "Category"
+	Category string `json:"category"`
+	// Sheet column "Discount Amount"
+	DiscountAmount string `json:"discountAmount"`
+	// Sheet column "Code"
+	Code string `json:"code"`
+	// Sheet column "Notes"
+	Notes string `json:"notes"`
+}
+
+// FacetCount is the FacetCount schema of the API
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// FreeProductRow is the FreeProductRow schema of the API
+type FreeProductRow struct {
+	// Sheet column "Date Added"
+	DateAdded time.Time `json:"dateAdded,omitzero"`
+	// Sheet column "Company"
+	Company string `json:"company"`
+	// Sheet column "Category"
+	Category string `json:"category"`
+	// Sheet column "Type"
+	Type string `json:"type"`
+	// Sheet column "Description"
+	Description string `json:"description"`
+	// Sheet column "How to Get in Touch"
+	HowToGetInTouch string `json:"howToGetInTouch"`
+	// Sheet column "Link"
+	Link string `json:"link"`
+}
+
+// PickupCardRow is the PickupCardRow schema of the API
+type PickupCardRow struct {
+	// Sheet column "Company"
+	Company CompanyField `json:"company"`
+	// Sheet column "Products"
+	Products string `json:"products"`
+	// Sheet column "Where"
+	Where string `json:"where"`
+	// Sheet column "Notes"
+	Notes string `json:"notes"`
+}
+
+// Problem is the Problem schema of the API
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// Resource is the Resource schema of the API
+type Resource struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Link        string `json:"link"`
+	SheetID     string `json:"sheetId,omitempty"`
+}
+
+// Row is the Row schema of the API
+type Row struct {
+	ID     string          `json:"id"`
+	Fields json.RawMessage `json:"fields"`
+}
+
+// ServiceCardRow is the ServiceCardRow schema of the API
+type ServiceCardRow struct {
+	// Sheet column "Date Added"
+	DateAdded time.Time `json:"dateAdded,omitzero"`
+	// Sheet column "Company"
+	Company CompanyField `json:"company"`
+	// Sheet column "Category"
+	Category string `json:"category"`
+	// Sheet column "How to Get in Touch"
+	HowToGetInTouch string `json:"howToGetInTouch"`
+	// Sheet column "Link"
+	Link string `json:"link"`
+	// Sheet column "Notes"
+	Notes string `json:"notes"`
+}
+
+// TabInfo is the TabInfo schema of the API
+type TabInfo struct {
+	Title     string `json:"title"`
+	HasConfig bool   `json:"hasConfig"`
+}
+
+// TabRows is the TabRows schema of the API
+type TabRows struct {
+	SheetID    string                  `json:"sheetId"`
+	Tab        string                  `json:"tab"`
+	Component  string                  `json:"component"`
+	Total      int                     `json:"total"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+	Facets     map[string][]FacetCount `json:"facets,omitempty"`
+	Rows       []Row                   `json:"rows"`
+}
+
+// ListCategories: Categories from the master sheet
+func (c *Client) ListCategories(ctx context.Context) ([]Category, error) {
+	var out []Category
+	if err := c.getJSON(ctx, "/api/v1/categories", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListResources: Resources in a category
+func (c *Client) ListResources(ctx context.Context, category string) ([]Resource, error) {
+	q := url.Values{}
+	q.Set("category", category)
+	var out []Resource
+	if err := c.getJSON(ctx, "/api/v1/resources", q, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListSheetTabs: Tabs of a configured sheet
+func (c *Client) ListSheetTabs(ctx context.Context, id string) ([]TabInfo, error) {
+	var out []TabInfo
+	if err := c.getJSON(ctx, "/api/v1/sheets/"+url.PathEscape(id)+"/tabs", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportTabRows: Download of the filtered rows of a configured tab
+func (c *Client) ExportTabRows(ctx context.Context, id string, tab string, query url.Values) ([]byte, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	return c.get(ctx, "/api/v1/sheets/"+url.PathEscape(id)+"/tabs/"+url.PathEscape(tab)+"/export", q)
+}
+
+// ListTabRows: Parsed rows of a configured tab
+func (c *Client) ListTabRows(ctx context.Context, id string, tab string, query url.Values) (*TabRows, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	var out TabRows
+	if err := c.getJSON(ctx, "/api/v1/sheets/"+url.PathEscape(id)+"/tabs/"+url.PathEscape(tab)+"/rows", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,175 @@
+package sheet_row_cards
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"disaster/i18n"
+)
+
+// Field describes a col-tagged field of a row struct
+type Field struct {
+	Index int    // index of the field in the struct
+	Name  string // Go field name
+	Col   string // sheet column header from the col tag
+	Key   string // JSON name, also used as the URL query key
+}
+
+// RowFields returns the col-tagged fields of a row type in struct order
+func RowFields(rowType reflect.Type) []Field {
+	var fields []Field
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		colName, ok := field.Tag.Lookup("col")
+		if !ok {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "" {
+			key = field.Name
+		}
+		fields = append(fields, Field{
+			Index: i,
+			Name:  field.Name,
+			Col:   colName,
+			Key:   key,
+		})
+	}
+	return fields
+}
+
+// FieldByKey returns the col-tagged field of a row type with the given key
+func FieldByKey(rowType reflect.Type, key string) (Field, bool) {
+	for _, field := range RowFields(rowType) {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// FieldByCol returns the col-tagged field of a row type with the given column header
+func FieldByCol(rowType reflect.Type, col string) (Field, bool) {
+	for _, field := range RowFields(rowType) {
+		if field.Col == col {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// IsTimeField reports whether a field holds a date
+func IsTimeField(rowType reflect.Type, field Field) bool {
+	return rowType.Field(field.Index).Type == reflect.TypeOf(time.Time{})
+}
+
+// FieldText returns the plain text of a row field as it appears on a card
+func FieldText(row any, field Field) string {
+	switch v := reflect.ValueOf(row).Field(field.Index).Interface().(type) {
+	case string:
+		return v
+	case CompanyField:
+		return v.Text
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02")
+	default:
+		return ""
+	}
+}
+
+// MatchesText reports whether every whitespace separated term of query
+// appears, case-insensitively, in at least one of the row's fields
+func MatchesText(row any, query string) bool {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return true
+	}
+
+	var text strings.Builder
+	for _, field := range RowFields(reflect.TypeOf(row)) {
+		text.WriteString(strings.ToLower(FieldText(row, field)))
+		text.WriteString("\n")
+	}
+
+	haystack := text.String()
+	for _, term := range terms {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortRows sorts rows by a field, keeping sheet order for equal values.
+// Rows with an empty value always sort last.
+func SortRows(rows []any, field Field, desc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a := reflect.ValueOf(rows[i]).Field(field.Index).Interface()
+		b := reflect.ValueOf(rows[j]).Field(field.Index).Interface()
+
+		if at, ok := a.(time.Time); ok {
+			bt := b.(time.Time)
+			if at.IsZero() || bt.IsZero() {
+				return !at.IsZero() && bt.IsZero()
+			}
+			if desc {
+				return at.After(bt)
+			}
+			return at.Before(bt)
+		}
+
+		as := strings.ToLower(FieldText(rows[i], field))
+		bs := strings.ToLower(FieldText(rows[j], field))
+		if as == "" || bs == "" {
+			return as != "" && bs == ""
+		}
+		if desc {
+			return as > bs
+		}
+		return as < bs
+	})
+}
+
+// SortOption is a choice in a tab view's sort menu
+type SortOption struct {
+	Value string // sort query parameter value
+	Label string
+}
+
+// ConfirmedSortKey sorts rows of Confirmable types by when they were last
+// confirmed working. It is not a field, so rows are sorted by the caller.
+const ConfirmedSortKey = "confirmed"
+
+// SortOptions returns the ascending and descending sort choices for every
+// field of a row type, labelled in the language of ctx. Dates list newest
+// first before oldest first. Confirmable rows can also be sorted by most
+// recently confirmed, and Locatable rows nearest first.
+func SortOptions(ctx context.Context, rowType reflect.Type) []SortOption {
+	var options []SortOption
+	if IsLocatable(rowType) {
+		options = append(options, SortOption{Value: DistanceSortKey, Label: i18n.T(ctx, "Nearest first")})
+	}
+	if IsConfirmable(rowType) {
+		options = append(options, SortOption{Value: "-" + ConfirmedSortKey, Label: i18n.T(ctx, "Most recently confirmed")})
+	}
+	for _, field := range RowFields(rowType) {
+		if IsTimeField(rowType, field) {
+			options = append(options,
+				SortOption{Value: "-" + field.Key, Label: i18n.T(ctx, "%s (newest first)", field.Col)},
+				SortOption{Value: field.Key, Label: i18n.T(ctx, "%s (oldest first)", field.Col)},
+			)
+			continue
+		}
+		options = append(options,
+			SortOption{Value: field.Key, Label: i18n.T(ctx, "%s (A-Z)", field.Col)},
+			SortOption{Value: "-" + field.Key, Label: i18n.T(ctx, "%s (Z-A)", field.Col)},
+		)
+	}
+	return options
+}
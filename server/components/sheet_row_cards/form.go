@@ -0,0 +1,126 @@
+package sheet_row_cards
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"disaster/i18n"
+)
+
+// maxFormValueLength caps the length of a suggested cell
+const maxFormValueLength = 2000
+
+// FormField is an input of a tab's suggestion form, generated from a
+// col-tagged row field. The field's form tag sets how it is asked for: "-"
+// leaves it out, "textarea" and "url" pick the input and "required" makes it
+// mandatory, e.g. `form:"textarea,required"`.
+type FormField struct {
+	Field
+	Input    string // "text", "textarea", "url" or "date"
+	Required bool
+	HasLink  bool // a CompanyField, which also takes a link
+}
+
+// LinkKey is the form key of the link of a CompanyField
+func (f FormField) LinkKey() string {
+	return f.Key + "Link"
+}
+
+// FormFields returns the suggestion form inputs of a row type in struct order
+func FormFields(rowType reflect.Type) []FormField {
+	var fields []FormField
+	for _, field := range RowFields(rowType) {
+		structField := rowType.Field(field.Index)
+		tag := structField.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+
+		formField := FormField{Field: field, Input: "text"}
+		switch structField.Type {
+		case reflect.TypeOf(time.Time{}):
+			formField.Input = "date"
+		case reflect.TypeOf(CompanyField{}):
+			formField.HasLink = true
+		}
+		for _, option := range strings.Split(tag, ",") {
+			switch option {
+			case "textarea", "url":
+				formField.Input = option
+			case "required":
+				formField.Required = true
+			}
+		}
+		fields = append(fields, formField)
+	}
+	return fields
+}
+
+// Suggestion is a row as entered in a suggestion form
+type Suggestion struct {
+	Values map[string]string // cell text by column header
+	Links  map[string]string // cell hyperlinks by column header
+}
+
+// ParseForm reads a suggestion for a row type from submitted form values. It
+// also returns what needs fixing, in the language of ctx, by form key.
+func ParseForm(ctx context.Context, rowType reflect.Type, form url.Values) (Suggestion, map[string]string) {
+	suggestion := Suggestion{Values: make(map[string]string), Links: make(map[string]string)}
+	problems := make(map[string]string)
+
+	check := func(key, value, input string, required bool) bool {
+		switch {
+		case value == "" && required:
+			problems[key] = i18n.T(ctx, "This field is required.")
+		case utf8.RuneCountInString(value) > maxFormValueLength:
+			problems[key] = i18n.T(ctx, "Keep this under %d characters.", maxFormValueLength)
+		case value != "" && input == "url" && !isWebURL(value):
+			problems[key] = i18n.T(ctx, "Enter a link starting with http:// or https://")
+		case value != "" && input == "date" && !isDate(value):
+			problems[key] = i18n.T(ctx, "Enter a date like 2025-01-31.")
+		default:
+			return true
+		}
+		return false
+	}
+
+	for _, field := range FormFields(rowType) {
+		value := strings.TrimSpace(form.Get(field.Key))
+		if check(field.Key, value, field.Input, field.Required) && value != "" {
+			suggestion.Values[field.Col] = value
+		}
+		if field.HasLink {
+			link := strings.TrimSpace(form.Get(field.LinkKey()))
+			if check(field.LinkKey(), link, "url", false) && link != "" {
+				suggestion.Links[field.Col] = link
+			}
+		}
+	}
+	return suggestion, problems
+}
+
+// isWebURL reports whether s is an absolute http or https URL
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isDate reports whether s is a date as sent by a date input
+func isDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// FillDates sets the empty date columns of an approved suggestion, such as
+// Date Added, to the date it was approved
+func FillDates(rowType reflect.Type, values map[string]string, approved time.Time) {
+	for _, field := range RowFields(rowType) {
+		if rowType.Field(field.Index).Type == reflect.TypeOf(time.Time{}) && values[field.Col] == "" {
+			values[field.Col] = approved.Format("2006-01-02")
+		}
+	}
+}
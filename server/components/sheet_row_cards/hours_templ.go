@@ -0,0 +1,126 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.857
+package sheet_row_cards
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+import (
+	"context"
+	"time"
+
+	"disaster/hours"
+	"disaster/i18n"
+)
+
+// OpenNowFilter is the value of the open query parameter that keeps only the
+// rows open at the time of the request
+const OpenNowFilter = "now"
+
+// HoursBadge says whether a place is open now and when that changes, such as
+// "Open now · Closes at 5pm". It depends on the time it is rendered, so it is
+// left out of static exports.
+func HoursBadge(status hours.Status) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		if status.Open {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<span class=\"inline-block mb-2 text-xs font-semibold px-2 py-1 rounded bg-green-100 text-green-800\" data-export=\"omit\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var2 string
+			templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(hoursLabel(ctx, status))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/sheet_row_cards/hours.templ`, Line: 21, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</span>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		} else {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<span class=\"inline-block mb-2 text-xs font-semibold px-2 py-1 rounded bg-gray-200 text-gray-700\" data-export=\"omit\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var3 string
+			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(hoursLabel(ctx, status))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/sheet_row_cards/hours.templ`, Line: 25, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</span>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		return nil
+	})
+}
+
+// hoursLabel describes an opening hours status in the language of ctx
+func hoursLabel(ctx context.Context, status hours.Status) string {
+	change := status.Change
+	switch {
+	case status.AlwaysOpen:
+		return i18n.T(ctx, "Open 24 hours")
+	case change.IsZero():
+		return i18n.T(ctx, "Closed")
+	}
+
+	var when string
+	today := status.At.Format(time.DateOnly)
+	switch change.Format(time.DateOnly) {
+	case today:
+		if status.Open {
+			when = i18n.T(ctx, "Closes at %s", i18n.FormatClock(ctx, change))
+		} else {
+			when = i18n.T(ctx, "Opens at %s", i18n.FormatClock(ctx, change))
+		}
+	case status.At.AddDate(0, 0, 1).Format(time.DateOnly):
+		if status.Open {
+			when = i18n.T(ctx, "Closes tomorrow %s", i18n.FormatClock(ctx, change))
+		} else {
+			when = i18n.T(ctx, "Opens tomorrow %s", i18n.FormatClock(ctx, change))
+		}
+	default:
+		if status.Open {
+			when = i18n.T(ctx, "Closes %[1]s %[2]s", i18n.FormatWeekday(ctx, change), i18n.FormatClock(ctx, change))
+		} else {
+			when = i18n.T(ctx, "Opens %[1]s %[2]s", i18n.FormatWeekday(ctx, change), i18n.FormatClock(ctx, change))
+		}
+	}
+	if status.Open {
+		return i18n.T(ctx, "Open now · %s", when)
+	}
+	return i18n.T(ctx, "Closed · %s", when)
+}
+
+var _ = templruntime.GeneratedTemplate
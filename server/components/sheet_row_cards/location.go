@@ -0,0 +1,18 @@
+package sheet_row_cards
+
+import "reflect"
+
+// Locatable is implemented by row types with an address that can be placed
+// on the map, such as pickup locations
+type Locatable interface {
+	Address() string
+}
+
+// IsLocatable reports whether rows of a type have an address
+func IsLocatable(rowType reflect.Type) bool {
+	return rowType.Implements(reflect.TypeOf((*Locatable)(nil)).Elem())
+}
+
+// DistanceSortKey sorts rows of Locatable types nearest first from a ZIP code
+// the visitor entered. It is not a field, so rows are sorted by the caller.
+const DistanceSortKey = "distance"
@@ -1,8 +1,11 @@
 package sheet_row_cards
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/a-h/templ"
@@ -35,10 +38,66 @@ func GetCardType(name string) (CardType, bool) {
 	return ct, ok
 }
 
+// RowID returns a stable identifier for a parsed row. It is derived from the
+// sheet, the tab and the values of the row's col fields, so it survives rows
+// being reordered but changes when any of the row's cells are edited.
+func RowID(sheetID, tabName string, row any) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%s", sheetID, tabName)
+
+	rowValue := reflect.ValueOf(row)
+	rowType := rowValue.Type()
+	for i := 0; i < rowType.NumField(); i++ {
+		if _, ok := rowType.Field(i).Tag.Lookup("col"); !ok {
+			continue
+		}
+		fmt.Fprintf(h, "\x00%v", rowValue.Field(i).Interface())
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// CardTypeNames returns the names of all registered card types in sorted order
+func CardTypeNames() []string {
+	names := make([]string, 0, len(cardTypes))
+	for name := range cardTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // CompanyField represents a company name that may have a hyperlink
 type CompanyField struct {
-	Text string
-	Link string
+	Text string `json:"text"`
+	Link string `json:"link,omitempty"`
+}
+
+// ParseDate reads a date as written in the sheets. An empty string is the
+// zero time.
+func ParseDate(dateStr string) (time.Time, error) {
+	if dateStr == "" {
+		return time.Time{}, nil
+	}
+	formats := []string{
+		"1/2/06",          // M/D/YY (2-digit year)
+		"01/02/06",        // MM/DD/YY (2-digit year)
+		"1/2/2006",        // M/D/YYYY
+		"01/02/2006",      // MM/DD/YYYY
+		"2006-01-02",      // YYYY-MM-DD
+		time.RFC3339,      // ISO format
+		"January 2, 2006", // Month D, YYYY
+		"Jan 2, 2006",     // Mon D, YYYY
+	}
+	var parseErr error
+	for _, format := range formats {
+		t, err := time.Parse(format, dateStr)
+		if err == nil {
+			return t, nil
+		}
+		parseErr = err
+	}
+	return time.Time{}, fmt.Errorf("failed to parse date with any format: %w", parseErr)
 }
 
 // convertValue converts a raw value from the sheet to the appropriate Go type
@@ -53,39 +112,16 @@ func convertValue(value interface{}, fieldType reflect.Type) (interface{}, error
 		if !ok {
 			return nil, fmt.Errorf("expected string for time.Time, got %T", value)
 		}
-		// Handle empty dates by returning zero time
-		if dateStr == "" {
-			return time.Time{}, nil
-		}
-		
-		// Try parsing with different formats
-		formats := []string{
-			"1/2/06",      // M/D/YY (2-digit year)
-			"01/02/06",    // MM/DD/YY (2-digit year)
-			"1/2/2006",    // M/D/YYYY
-			"01/02/2006",  // MM/DD/YYYY
-			"2006-01-02",  // YYYY-MM-DD
-			time.RFC3339,  // ISO format
-			"January 2, 2006", // Month D, YYYY
-			"Jan 2, 2006",    // Mon D, YYYY
-		}
-
-		var parseErr error
-		for _, format := range formats {
-			if t, err := time.Parse(format, dateStr); err == nil {
-				return t, nil
-			} else {
-				parseErr = err
-			}
-		}
-		return nil, fmt.Errorf("failed to parse date with any format: %w", parseErr)
+		return ParseDate(dateStr)
 	case "CompanyField":
 		// Handle CompanyField type
 		switch v := value.(type) {
 		case map[string]interface{}:
+			text, _ := v["text"].(string)
+			link, _ := v["link"].(string)
 			return CompanyField{
-				Text: v["text"].(string),
-				Link: v["link"].(string),
+				Text: text,
+				Link: link,
 			}, nil
 		case string:
 			return CompanyField{
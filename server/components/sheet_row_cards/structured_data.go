@@ -0,0 +1,136 @@
+package sheet_row_cards
+
+import (
+	"strings"
+	"time"
+)
+
+// structuredDataRow is implemented by row types that can describe themselves
+// as a schema.org item
+type structuredDataRow interface {
+	StructuredData() map[string]any
+}
+
+// StructuredData returns the schema.org JSON-LD item of a row, or nil when
+// its type has none
+func StructuredData(row any) map[string]any {
+	r, ok := row.(structuredDataRow)
+	if !ok {
+		return nil
+	}
+	data := r.StructuredData()
+	data["@context"] = "https://schema.org"
+	return compact(data)
+}
+
+// StructuredData describes a discount as an Offer from the company
+func (r DiscountRow) StructuredData() map[string]any {
+	return map[string]any{
+		"@type":       "Offer",
+		"name":        joinNonEmpty(" - ", r.DiscountAmount, r.Company.Text),
+		"description": joinNonEmpty(". ", r.Notes, prefixNonEmpty("Code: ", r.Code)),
+		"category":    r.Category,
+		"url":         r.Company.Link,
+		"validFrom":   dateOrEmpty(r.DateAdded),
+		"offeredBy":   organization(r.Company.Text, r.Company.Link),
+	}
+}
+
+// StructuredData describes a free product as an Offer at no cost
+func (r FreeProductRow) StructuredData() map[string]any {
+	return map[string]any{
+		"@type":         "Offer",
+		"name":          joinNonEmpty(" - ", r.Type, r.Company),
+		"description":   r.Description,
+		"category":      r.Category,
+		"url":           r.Link,
+		"price":         "0",
+		"priceCurrency": "USD",
+		"validFrom":     dateOrEmpty(r.DateAdded),
+		"offeredBy":     organization(r.Company, ""),
+		"itemOffered": map[string]any{
+			"@type": "Product",
+			"name":  r.Type,
+		},
+	}
+}
+
+// StructuredData describes a pickup location as a Place
+func (r PickupCardRow) StructuredData() map[string]any {
+	return map[string]any{
+		"@type":       "Place",
+		"name":        r.Company.Text,
+		"url":         r.Company.Link,
+		"address":     r.Where,
+		"description": joinNonEmpty(". ", r.Products, r.Notes),
+	}
+}
+
+// StructuredData describes a service as a Service provided by the company
+func (r ServiceCardRow) StructuredData() map[string]any {
+	return map[string]any{
+		"@type":       "Service",
+		"name":        r.Company.Text,
+		"serviceType": r.Category,
+		"description": r.Notes,
+		"url":         r.Link,
+		"provider":    organization(r.Company.Text, r.Company.Link),
+	}
+}
+
+// organization returns a schema.org Organization, or nil without a name
+func organization(name, link string) map[string]any {
+	if name == "" {
+		return nil
+	}
+	return map[string]any{
+		"@type": "Organization",
+		"name":  name,
+		"url":   link,
+	}
+}
+
+// compact drops empty values from a JSON-LD item and the items nested in it
+func compact(data map[string]any) map[string]any {
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				delete(data, key)
+			}
+		case map[string]any:
+			if v == nil {
+				delete(data, key)
+			} else {
+				data[key] = compact(v)
+			}
+		case nil:
+			delete(data, key)
+		}
+	}
+	return data
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+func prefixNonEmpty(prefix, s string) string {
+	if s == "" {
+		return ""
+	}
+	return prefix + s
+}
+
+func dateOrEmpty(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,58 @@
+package sheet_row_cards
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"strings"
+)
+
+// CardText renders a card and returns its visible text, for places HTML
+// cannot go such as feed entries and emails
+func CardText(ctx context.Context, render CardRenderer, row any) (string, error) {
+	var buf bytes.Buffer
+	if err := render(row).Render(ctx, &buf); err != nil {
+		return "", err
+	}
+	return htmlText(buf.String()), nil
+}
+
+// htmlText strips the tags from rendered card HTML, dropping the contents of
+// svg, script and style elements, and collapses whitespace
+func htmlText(markup string) string {
+	var text strings.Builder
+	skipUntil := ""
+
+	for len(markup) > 0 {
+		start := strings.IndexByte(markup, '<')
+		if start < 0 {
+			if skipUntil == "" {
+				text.WriteString(markup)
+			}
+			break
+		}
+		if skipUntil == "" {
+			text.WriteString(markup[:start])
+		}
+
+		end := strings.IndexByte(markup[start:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToLower(markup[start+1 : start+end])
+		markup = markup[start+end+1:]
+
+		name := strings.TrimPrefix(strings.Fields(tag + " ")[0], "/")
+		switch {
+		case skipUntil != "":
+			if tag == "/"+skipUntil {
+				skipUntil = ""
+			}
+		case (name == "svg" || name == "script" || name == "style") && !strings.HasPrefix(tag, "/") && !strings.HasSuffix(tag, "/"):
+			skipUntil = name
+		}
+		text.WriteString(" ")
+	}
+
+	return strings.Join(strings.Fields(html.UnescapeString(text.String())), " ")
+}
@@ -0,0 +1,71 @@
+package sheet_row_cards
+
+import (
+	"regexp"
+
+	"golang.org/x/text/language"
+)
+
+// translatedColumn matches translated column headers such as
+// "Description (es)" or "Notes (pt-BR)"
+var translatedColumn = regexp.MustCompile(`^(.+) \(([A-Za-z]{2,3}(?:-[A-Za-z0-9]{2,4})?)\)$`)
+
+// TranslatedColumns finds the translated columns of a header row, given as
+// the column indices by header. It returns the index of each translated
+// column by language and untranslated header.
+func TranslatedColumns(colMap map[string]int) map[language.Tag]map[string]int {
+	translated := make(map[language.Tag]map[string]int)
+	for header, idx := range colMap {
+		m := translatedColumn.FindStringSubmatch(header)
+		if m == nil {
+			continue
+		}
+		lang, err := language.Parse(m[2])
+		if err != nil {
+			continue
+		}
+		if translated[lang] == nil {
+			translated[lang] = make(map[string]int)
+		}
+		translated[lang][m[1]] = idx
+	}
+	return translated
+}
+
+// ParseTranslatedRow parses a row like ParseRowData, reading each field from
+// its translated column when the row has text there. It reports false when
+// the row has no translated text.
+func ParseTranslatedRow(cardType CardType, row []interface{}, colMap, translated map[string]int) (any, bool, error) {
+	merged := make(map[string]int, len(colMap))
+	for col, idx := range colMap {
+		merged[col] = idx
+	}
+
+	found := false
+	for col, idx := range translated {
+		if idx >= len(row) || cellEmpty(row[idx]) {
+			continue
+		}
+		merged[col] = idx
+		found = true
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	parsed, err := ParseRowData(cardType, row, merged)
+	return parsed, err == nil, err
+}
+
+// cellEmpty reports whether a sheet cell, plain or linked text, has no text
+func cellEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		text, _ := v["text"].(string)
+		return text == ""
+	default:
+		return value == nil
+	}
+}
@@ -0,0 +1,29 @@
+package components
+
+import "net/url"
+
+// CategoryURL returns the page URL of a category
+func CategoryURL(category string) string {
+	return "/c/" + url.PathEscape(category)
+}
+
+// SheetURL returns the page URL of a sheet linked from a category
+func SheetURL(category, sheetID string) string {
+	return CategoryURL(category) + "/s/" + url.PathEscape(sheetID)
+}
+
+// TabURL returns the page URL of a sheet tab
+func TabURL(category, sheetID, tabName string) string {
+	return SheetURL(category, sheetID) + "/t/" + url.PathEscape(tabName)
+}
+
+// RowURL returns the permalink of a single row of a sheet tab
+func RowURL(category, sheetID, tabName, rowID string) string {
+	return TabURL(category, sheetID, tabName) + "/r/" + url.PathEscape(rowID)
+}
+
+// CompanyURL returns the page of every offer from a company listed more than
+// once, by the key of its duplicates cluster
+func CompanyURL(key string) string {
+	return "/companies/" + url.PathEscape(key)
+}
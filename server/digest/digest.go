@@ -0,0 +1,173 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/gdrive"
+	"disaster/i18n"
+	"disaster/mail"
+	"disaster/snapshot"
+)
+
+// checkInterval is how often Run looks for digests that are due
+const checkInterval = 15 * time.Minute
+
+// BaseURL returns the public URL of the site used for links in email, from
+// BASE_URL, and whether it is set. Without it no subscription email can link
+// back to the site, so subscriptions and digests are off.
+func BaseURL() (string, bool) {
+	base := os.Getenv("BASE_URL")
+	return strings.TrimSuffix(base, "/"), base != ""
+}
+
+// Run sends the digests that are due until ctx is done. It does nothing when
+// BASE_URL is unset.
+func Run(ctx context.Context, mailer mail.Mailer) {
+	if _, ok := BaseURL(); !ok {
+		log.Printf("Digest: BASE_URL is not set, not sending digests")
+		return
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		SendDue(ctx, mailer, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue emails every confirmed subscriber whose digest is due at now the
+// rows added or changed since their last one. Subscribers with nothing new
+// get no email. Subscriptions never confirmed within confirmWithin are
+// purged first.
+func SendDue(ctx context.Context, mailer mail.Mailer, now time.Time) {
+	base, ok := BaseURL()
+	if !ok {
+		return
+	}
+	purgeUnconfirmed(now)
+	subs := due(now)
+	if len(subs) == 0 {
+		return
+	}
+	// Taking the current snapshot refreshes it when it is stale, which
+	// records the latest changes
+	snap := snapshot.Current(ctx)
+
+	for _, sub := range subs {
+		changes := matching(snap, sub, snapshot.ChangesSince(sub.LastSentAt))
+		if len(changes) > 0 {
+			if err := mailer.Send(ctx, digestMessage(base, sub, changes)); err != nil {
+				log.Printf("Digest: error sending %s digest for %s: %v", sub.Frequency, sub.Target(), err)
+				continue
+			}
+			log.Printf("Digest: sent %d changes in %s", len(changes), sub.Target())
+		}
+		markSent(sub.Token, now)
+	}
+}
+
+// SendConfirmation emails a new subscriber the link confirming their
+// subscription
+func SendConfirmation(ctx context.Context, mailer mail.Mailer, sub Subscription) error {
+	base, ok := BaseURL()
+	if !ok {
+		return apperr.New(apperr.NotConfigured, "Email updates aren't available on mili.fit right now.")
+	}
+	ctx = subscriberContext(ctx, sub)
+	body := strings.Join([]string{
+		i18n.T(ctx, "Confirm that you want a %s digest of new and changed listings in %s on mili.fit:", i18n.T(ctx, string(sub.Frequency)), sub.Target()),
+		"",
+		base + "/subscriptions/confirm?token=" + sub.Token,
+		"",
+		i18n.T(ctx, "If you didn't ask for this, ignore this email and you won't hear from us again."),
+	}, "\n")
+	return mailer.Send(ctx, mail.Message{
+		To:      sub.Email,
+		Subject: i18n.T(ctx, "Confirm your mili.fit subscription"),
+		Body:    body,
+	})
+}
+
+// matching returns the changes a subscription follows, one per row
+func matching(snap *snapshot.Snapshot, sub Subscription, changes []snapshot.Change) []snapshot.Change {
+	seen := make(map[string]bool)
+	var matched []snapshot.Change
+	for _, change := range changes {
+		if seen[change.RowID] {
+			continue
+		}
+		if sub.SheetID != "" {
+			if change.SheetID != sub.SheetID || change.TabName != sub.TabName {
+				continue
+			}
+		} else if !inCategory(snap, change.SheetID, sub.Category) {
+			continue
+		}
+		seen[change.RowID] = true
+		matched = append(matched, change)
+	}
+	return matched
+}
+
+// inCategory reports whether a resource of the category links to the sheet
+func inCategory(snap *snapshot.Snapshot, sheetID, category string) bool {
+	for _, resource := range snap.Resources {
+		if resource.Category == category && gdrive.ExtractGoogleDocID(resource.Link) == sheetID {
+			return true
+		}
+	}
+	return false
+}
+
+// digestMessage writes the digest email of a subscription, linking to the
+// site at base
+func digestMessage(base string, sub Subscription, changes []snapshot.Change) mail.Message {
+	ctx := subscriberContext(context.Background(), sub)
+	unsubscribeURL := base + "/subscriptions/unsubscribe?token=" + sub.Token
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", i18n.T(ctx, "New and changed listings in %s since %s:", sub.Target(), i18n.FormatDate(ctx, sub.LastSentAt)))
+	for _, change := range changes {
+		label := i18n.T(ctx, "New")
+		if change.Kind == snapshot.Changed {
+			label = i18n.T(ctx, "Changed")
+		}
+		fmt.Fprintf(&b, "%s: %s (%s)\n", label, change.Title, change.TabName)
+		fmt.Fprintf(&b, "%s%s\n\n", base, components.RowURL(sub.Category, change.SheetID, change.TabName, change.RowID))
+	}
+	fmt.Fprintf(&b, "--\n%s\n%s\n",
+		i18n.T(ctx, "You're getting this %s digest because you subscribed to %s on mili.fit.", i18n.T(ctx, string(sub.Frequency)), sub.Target()),
+		i18n.T(ctx, "Unsubscribe: %s", unsubscribeURL))
+
+	return mail.Message{
+		To:      sub.Email,
+		Subject: i18n.T(ctx, "%d new and changed listings in %s", len(changes), sub.Target()),
+		Body:    b.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}
+}
+
+// subscriberContext returns ctx set to the subscriber's language
+func subscriberContext(ctx context.Context, sub Subscription) context.Context {
+	lang, err := language.Parse(sub.Language)
+	if err != nil {
+		lang = language.English
+	}
+	return i18n.WithLanguage(ctx, lang)
+}
@@ -0,0 +1,272 @@
+// Package digest emails subscribers a daily or weekly digest of the rows
+// added or changed in a category or tab, built from the snapshot change log.
+package digest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"disaster/apperr"
+	"disaster/store"
+)
+
+// subscriptionsFile holds every subscription by token
+const subscriptionsFile = "subscriptions.json"
+
+// confirmWithin is how long a new address has to open its confirmation link
+// before the subscription is forgotten
+const confirmWithin = 7 * 24 * time.Hour
+
+// Frequency is how often a digest is sent
+type Frequency string
+
+const (
+	Daily  Frequency = "daily"
+	Weekly Frequency = "weekly"
+)
+
+// Period returns the time between digests
+func (f Frequency) Period() time.Duration {
+	if f == Weekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Subscription is an email address following a category or a tab. It only
+// gets digests once the address is confirmed.
+type Subscription struct {
+	Token     string    `json:"token"` // secret used in confirm and unsubscribe links
+	Email     string    `json:"email"`
+	Frequency Frequency `json:"frequency"`
+	Category  string    `json:"category"`
+	SheetID   string    `json:"sheetId,omitempty"` // set for tab subscriptions
+	TabName   string    `json:"tabName,omitempty"`
+	Language  string    `json:"language"` // BCP 47 tag digests are written in
+	CreatedAt time.Time `json:"createdAt"`
+	Confirmed bool      `json:"confirmed"`
+	// LastSentAt is when the last digest was sent, or when the subscription
+	// was confirmed; the next digest covers the changes since
+	LastSentAt time.Time `json:"lastSentAt,omitzero"`
+	// NewFrequency is a frequency asked for since the subscription was
+	// confirmed, applied once the address opens the confirmation link again
+	NewFrequency Frequency `json:"newFrequency,omitempty"`
+}
+
+// Target describes what the subscription follows, e.g. "Food" or
+// "Food / Company List - Discount Codes"
+func (s Subscription) Target() string {
+	if s.SheetID != "" {
+		return s.Category + " / " + s.TabName
+	}
+	return s.Category
+}
+
+var (
+	mu            sync.Mutex
+	subscriptions map[string]*Subscription
+)
+
+// load reads the subscriptions from the store once. It must be called with mu
+// held.
+func load() {
+	if subscriptions != nil {
+		return
+	}
+	subscriptions = make(map[string]*Subscription)
+	if err := store.Load(subscriptionsFile, &subscriptions); err != nil {
+		log.Printf("Digest: error loading subscriptions: %v", err)
+	}
+}
+
+// save writes the subscriptions to the store. It must be called with mu held.
+func save() error {
+	return store.Save(subscriptionsFile, subscriptions)
+}
+
+// Subscribe adds an unconfirmed subscription, or updates the frequency of an
+// existing one for the same address and target, and returns it, with the
+// frequency asked for, so a confirmation link can be sent. A confirmed
+// subscription keeps its frequency until the link is opened. Unconfirmed
+// subscriptions are purged once they are confirmWithin old.
+func Subscribe(sub Subscription) (Subscription, error) {
+	addr, err := mail.ParseAddress(sub.Email)
+	if err != nil || strings.ContainsAny(addr.Address, "\r\n") {
+		return Subscription{}, apperr.New(apperr.BadInput, "Enter a valid email address.")
+	}
+	sub.Email = addr.Address
+	if sub.Frequency != Daily && sub.Frequency != Weekly {
+		return Subscription{}, apperr.New(apperr.BadInput, "Choose daily or weekly.")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	for _, existing := range subscriptions {
+		sameTarget := existing.Category == sub.Category && existing.SheetID == sub.SheetID && existing.TabName == sub.TabName
+		if sameTarget && strings.EqualFold(existing.Email, sub.Email) {
+			previous := *existing
+			if existing.Confirmed {
+				// Anyone can enter the address, so the change waits for the
+				// link emailed to it
+				existing.NewFrequency = sub.Frequency
+			} else {
+				existing.Frequency = sub.Frequency
+				// The confirmation link sent again gets a full week
+				existing.CreatedAt = time.Now()
+			}
+			if err := save(); err != nil {
+				*existing = previous
+				return Subscription{}, err
+			}
+			confirming := *existing
+			confirming.Frequency = sub.Frequency
+			return confirming, nil
+		}
+	}
+
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return Subscription{}, fmt.Errorf("generating subscription token: %w", err)
+	}
+	sub.Token = base64.RawURLEncoding.EncodeToString(token)
+	sub.CreatedAt = time.Now()
+	sub.Confirmed = false
+	subscriptions[sub.Token] = &sub
+	if err := save(); err != nil {
+		delete(subscriptions, sub.Token)
+		return Subscription{}, err
+	}
+	return sub, nil
+}
+
+// Confirm confirms the subscription with the given token, applying any
+// frequency asked for since it was confirmed
+func Confirm(token string) (Subscription, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	sub, ok := subscriptions[token]
+	if !ok || expired(sub, time.Now()) {
+		return Subscription{}, apperr.New(apperr.NotFound, "This subscription link has expired or was already used to unsubscribe")
+	}
+	if sub.Confirmed && sub.NewFrequency == "" {
+		return *sub, nil
+	}
+	previous := *sub
+	if sub.Confirmed {
+		sub.Frequency, sub.NewFrequency = sub.NewFrequency, ""
+	} else {
+		sub.Confirmed = true
+		sub.LastSentAt = time.Now()
+	}
+	if err := save(); err != nil {
+		*sub = previous
+		return Subscription{}, err
+	}
+	return *sub, nil
+}
+
+// Get returns the subscription with the given token
+func Get(token string) (Subscription, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	sub, ok := subscriptions[token]
+	if !ok {
+		return Subscription{}, false
+	}
+	return *sub, true
+}
+
+// Unsubscribe removes the subscription with the given token
+func Unsubscribe(token string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	sub, ok := subscriptions[token]
+	if !ok {
+		return nil
+	}
+	delete(subscriptions, token)
+	if err := save(); err != nil {
+		subscriptions[token] = sub
+		return err
+	}
+	return nil
+}
+
+// expired reports whether sub was never confirmed and its confirmation link
+// has run out at now
+func expired(sub *Subscription, now time.Time) bool {
+	return !sub.Confirmed && now.Sub(sub.CreatedAt) >= confirmWithin
+}
+
+// purgeUnconfirmed forgets the subscriptions whose confirmation link ran out
+// at now, so addresses that never confirmed aren't kept
+func purgeUnconfirmed(now time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	purged := make(map[string]*Subscription)
+	for token, sub := range subscriptions {
+		if expired(sub, now) {
+			purged[token] = sub
+			delete(subscriptions, token)
+		}
+	}
+	if len(purged) == 0 {
+		return
+	}
+	if err := save(); err != nil {
+		log.Printf("Digest: error purging unconfirmed subscriptions: %v", err)
+		for token, sub := range purged {
+			subscriptions[token] = sub
+		}
+		return
+	}
+	log.Printf("Digest: purged %d unconfirmed subscriptions", len(purged))
+}
+
+// due returns the confirmed subscriptions whose next digest is due at now
+func due(now time.Time) []Subscription {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	var list []Subscription
+	for _, sub := range subscriptions {
+		if sub.Confirmed && now.Sub(sub.LastSentAt) >= sub.Frequency.Period() {
+			list = append(list, *sub)
+		}
+	}
+	return list
+}
+
+// markSent records that a subscription's digest covering changes up to sentAt
+// was handled
+func markSent(token string, sentAt time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	sub, ok := subscriptions[token]
+	if !ok {
+		return
+	}
+	sub.LastSentAt = sentAt
+	if err := save(); err != nil {
+		log.Printf("Digest: error saving subscription: %v", err)
+	}
+}
@@ -0,0 +1,350 @@
+// Package duplicates finds rows that look like the same company across every
+// configured tab, such as a company listed under both free products and free
+// services, or twice in one tab with its name spelled differently.
+package duplicates
+
+import (
+	"log"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"disaster/components/sheet_row_cards"
+	"disaster/snapshot"
+)
+
+// Merge is whether visitors see every offer from a company listed more than
+// once on one page, from MERGE_DUPLICATES. Suspected duplicates are listed
+// to admins either way.
+var Merge = merge()
+
+func merge() bool {
+	value := os.Getenv("MERGE_DUPLICATES")
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Duplicates: invalid MERGE_DUPLICATES %q, not merging", value)
+	}
+	return enabled
+}
+
+// minSimilarLength is the shortest normalized name compared by similarity;
+// shorter names have to match exactly
+const minSimilarLength = 5
+
+// minSimilarity is how alike two names have to be to count as the same
+// company, from 0 to 1
+const minSimilarity = 0.85
+
+// Reason says why rows were put in the same cluster
+type Reason string
+
+const (
+	SameName    Reason = "same name"
+	SimilarName Reason = "similar name"
+	SameWebsite Reason = "same website"
+)
+
+// Member is a row in a cluster
+type Member struct {
+	SheetID string
+	TabName string
+	RowID   string
+	Title   string
+	Link    string
+	Row     any
+}
+
+// Cluster is rows that look like the same company
+type Cluster struct {
+	Key     string // URL-safe name of the cluster, from its first row's name
+	Name    string // the first row's title
+	Members []Member
+	Reasons []Reason
+	// Mergeable is whether the rows are joined by the same name or website
+	// alone. A cluster that needs a similar name to hold together may be a
+	// chain of different companies, each a typo from the next, so it is only
+	// listed to admins.
+	Mergeable bool
+}
+
+// genericHosts are sites many unrelated companies link to, so sharing one,
+// or any of its subdomains, says nothing about two rows
+var genericHosts = map[string]bool{
+	"google.com": true, "forms.gle": true, "goo.gl": true, "bit.ly": true,
+	"linktr.ee": true, "instagram.com": true, "facebook.com": true, "fb.com": true,
+	"twitter.com": true, "x.com": true, "tiktok.com": true, "youtube.com": true,
+	"linkedin.com": true, "gofundme.com": true, "eventbrite.com": true, "amazon.com": true,
+	"apple.com": true, "wixsite.com": true, "squarespace.com": true, "square.site": true,
+	"business.site": true, "github.io": true, "wordpress.com": true, "blogspot.com": true,
+}
+
+// secondLevelDomains are labels under a country code that sites register
+// below, as in example.co.uk
+var secondLevelDomains = map[string]bool{
+	"co": true, "com": true, "org": true, "net": true, "gov": true, "edu": true,
+	"ac": true, "gob": true, "nic": true, "ne": true, "or": true,
+}
+
+// legalWords are dropped from company names before comparing them
+var legalWords = map[string]bool{
+	"the": true, "inc": true, "llc": true, "ltd": true, "co": true,
+	"corp": true, "corporation": true, "company": true,
+}
+
+// NormalizeName reduces a company name to lowercase words without
+// punctuation or legal suffixes, so "The Acme Co." and "ACME" compare equal
+func NormalizeName(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := words[:0]
+	for _, word := range words {
+		if !legalWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// NormalizeLink reduces a link to its registrable domain, so
+// "https://shop.acme.com/x" and "acme.com" compare equal, or "" when it isn't
+// a web link or is on a site many companies share
+func NormalizeLink(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	if !strings.Contains(link, "://") {
+		// A scheme without a dot, as in mailto: or tel:, isn't a host
+		if scheme, _, ok := strings.Cut(link, ":"); ok && !strings.ContainsAny(scheme, "./") {
+			return ""
+		}
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.Contains(u.Hostname(), ".") {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for suffix := host; ; {
+		if genericHosts[suffix] {
+			return ""
+		}
+		_, parent, ok := strings.Cut(suffix, ".")
+		if !ok {
+			break
+		}
+		suffix = parent
+	}
+	return registrableDomain(host)
+}
+
+// registrableDomain returns the part of host a site registers, such as
+// acme.com for shop.acme.com or acme.co.uk for www.acme.co.uk. It knows the
+// common country code second-level domains rather than the full public
+// suffix list.
+func registrableDomain(host string) string {
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) > 2 && len(labels[len(labels)-1]) == 2 && secondLevelDomains[labels[len(labels)-2]] {
+		n = 3
+	}
+	if len(labels) <= n {
+		return host
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// Find clusters the rows of every tab in snap that look like the same
+// company: the same name once normalized, names that differ by a typo, or
+// links to the same website. Only clusters of two or more rows are returned,
+// largest first. Matches chain, so a cluster holds every row reachable from
+// another by matches; clusters held together only by same names and websites
+// are Mergeable.
+func Find(snap *snapshot.Snapshot) []Cluster {
+	var members []Member
+	var names, hosts []string
+	for _, tab := range snap.Tabs {
+		for _, row := range tab.Rows {
+			member := Member{
+				SheetID: tab.SheetID,
+				TabName: tab.TabName,
+				RowID:   tab.RowID(row),
+				Title:   tab.Title(row),
+				Link:    rowLink(tab, row),
+				Row:     row,
+			}
+			members = append(members, member)
+			names = append(names, NormalizeName(member.Title))
+			hosts = append(hosts, NormalizeLink(member.Link))
+		}
+	}
+
+	sets := newUnionFind(len(members))
+	strong := newUnionFind(len(members))
+	reasons := make(map[[2]int]Reason)
+	for i := range members {
+		for j := i + 1; j < len(members); j++ {
+			if reason, ok := match(names[i], names[j], hosts[i], hosts[j]); ok {
+				sets.union(i, j)
+				if reason != SimilarName {
+					strong.union(i, j)
+				}
+				reasons[[2]int{i, j}] = reason
+			}
+		}
+	}
+
+	byRoot := make(map[int][]int)
+	for i := range members {
+		root := sets.find(i)
+		byRoot[root] = append(byRoot[root], i)
+	}
+
+	var clusters []Cluster
+	for _, indexes := range byRoot {
+		if len(indexes) < 2 {
+			continue
+		}
+		sort.Ints(indexes)
+		cluster := Cluster{Name: members[indexes[0]].Title, Mergeable: true}
+		seen := make(map[Reason]bool)
+		for a, i := range indexes {
+			cluster.Members = append(cluster.Members, members[i])
+			if strong.find(i) != strong.find(indexes[0]) {
+				cluster.Mergeable = false
+			}
+			for _, j := range indexes[a+1:] {
+				if reason, ok := reasons[[2]int{i, j}]; ok && !seen[reason] {
+					seen[reason] = true
+					cluster.Reasons = append(cluster.Reasons, reason)
+				}
+			}
+		}
+		cluster.Key = slug(names[indexes[0]])
+		clusters = append(clusters, cluster)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Members) != len(clusters[j].Members) {
+			return len(clusters[i].Members) > len(clusters[j].Members)
+		}
+		return clusters[i].Key < clusters[j].Key
+	})
+	// Keys are unique so each cluster can have its own page
+	keys := make(map[string]int)
+	for i := range clusters {
+		keys[clusters[i].Key]++
+		if n := keys[clusters[i].Key]; n > 1 {
+			clusters[i].Key += "-" + strconv.Itoa(n)
+		}
+	}
+	return clusters
+}
+
+// match reports whether two rows look like the same company, from their
+// normalized names and hosts
+func match(nameA, nameB, hostA, hostB string) (Reason, bool) {
+	switch {
+	case nameA != "" && nameA == nameB:
+		return SameName, true
+	case hostA != "" && hostA == hostB:
+		return SameWebsite, true
+	case len(nameA) >= minSimilarLength && len(nameB) >= minSimilarLength && similarity(nameA, nameB) >= minSimilarity:
+		return SimilarName, true
+	}
+	return "", false
+}
+
+// similarity is 1 minus the edit distance between a and b relative to the
+// longer of them
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	// Names this different in length can't be similar enough
+	if float64(abs(len(ra)-len(rb)))/float64(longest) > 1-minSimilarity {
+		return 0
+	}
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// rowLink returns a row's website: its Link column, or the link on its
+// Company column
+func rowLink(tab *snapshot.Tab, row any) string {
+	if field, ok := sheet_row_cards.FieldByCol(tab.CardType.RowType, "Link"); ok {
+		if link := sheet_row_cards.FieldText(row, field); link != "" {
+			return link
+		}
+	}
+	if field, ok := sheet_row_cards.FieldByCol(tab.CardType.RowType, "Company"); ok {
+		if company, ok := reflect.ValueOf(row).Field(field.Index).Interface().(sheet_row_cards.CompanyField); ok {
+			return company.Link
+		}
+	}
+	return ""
+}
+
+// slug turns a normalized name into a URL path segment
+func slug(name string) string {
+	if name == "" {
+		return "unnamed"
+	}
+	return strings.ReplaceAll(name, " ", "-")
+}
+
+// unionFind groups the indexes of rows into disjoint sets
+type unionFind []int
+
+func newUnionFind(n int) unionFind {
+	sets := make(unionFind, n)
+	for i := range sets {
+		sets[i] = i
+	}
+	return sets
+}
+
+func (u unionFind) find(i int) int {
+	for u[i] != i {
+		u[i] = u[u[i]]
+		i = u[i]
+	}
+	return i
+}
+
+func (u unionFind) union(i, j int) {
+	u[u.find(i)] = u.find(j)
+}
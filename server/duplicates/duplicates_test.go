@@ -0,0 +1,190 @@
+package duplicates
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"disaster/components/sheet_row_cards"
+	"disaster/snapshot"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"The Acme Co.", "acme"},
+		{"ACME, Inc", "acme"},
+		{"Smith & Sons LLC", "smith and sons"},
+		{"Bob's Tire-Shop", "bob s tire shop"},
+		{"The Company", ""},
+	}
+	for _, test := range tests {
+		if got := NormalizeName(test.name); got != test.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestNormalizeLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"https://shop.acme.com/x", "acme.com"},
+		{"acme.com", "acme.com"},
+		{"  HTTP://WWW.Acme.COM./about  ", "acme.com"},
+		{"https://www.acme.co.uk", "acme.co.uk"},
+		{"https://acme.co.uk", "acme.co.uk"},
+		{"https://shop.acme.com.mx", "acme.com.mx"},
+		// Only two-letter country codes have second-level domains
+		{"https://shop.acme.co.com", "co.com"},
+		// Shared sites and their subdomains say nothing about a company
+		{"https://www.facebook.com/acme", ""},
+		{"https://m.facebook.com/acme", ""},
+		{"https://docs.google.com/forms/d/1", ""},
+		{"forms.gle/abc", ""},
+		{"https://acme.wixsite.com/home", ""},
+		{"https://acme.github.io", ""},
+		// A site merely ending in a shared site's name is not that site
+		{"https://notfacebook.com", "notfacebook.com"},
+		{"mailto:help@acme.com", ""},
+		{"tel:+15550100", ""},
+		{"acme.com:8080/help", "acme.com"},
+		{"ftp://acme.com", ""},
+		{"localhost", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := NormalizeLink(test.link); got != test.want {
+			t.Errorf("NormalizeLink(%q) = %q, want %q", test.link, got, test.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		nameA, nameB string
+		hostA, hostB string
+		want         Reason
+		ok           bool
+	}{
+		{"acme", "acme", "", "", SameName, true},
+		{"acme", "acme", "acme.com", "acme.com", SameName, true},
+		{"acme", "zenith", "acme.com", "acme.com", SameWebsite, true},
+		{"food bank", "food bnk", "", "", SimilarName, true},
+		{"food bank", "food bnak", "", "", "", false}, // a swap is two edits
+		{"acme", "acne", "", "", "", false},           // too short to compare by similarity
+		{"food bank", "fuel depot", "", "", "", false},
+		{"", "", "", "", "", false},
+	}
+	for _, test := range tests {
+		reason, ok := match(test.nameA, test.nameB, test.hostA, test.hostB)
+		if reason != test.want || ok != test.ok {
+			t.Errorf("match(%q, %q, %q, %q) = %q, %v, want %q, %v",
+				test.nameA, test.nameB, test.hostA, test.hostB, reason, ok, test.want, test.ok)
+		}
+	}
+}
+
+type offerRow struct {
+	Company sheet_row_cards.CompanyField `col:"Company" json:"company"`
+	Link    string                       `col:"Link" json:"link"`
+}
+
+// offer returns a row for a company, linked from its name or its Link column
+func offer(name, companyLink, link string) offerRow {
+	return offerRow{Company: sheet_row_cards.CompanyField{Text: name, Link: companyLink}, Link: link}
+}
+
+// testSnapshot returns a snapshot with a tab of products and one of services
+func testSnapshot(products, services []offerRow) *snapshot.Snapshot {
+	cardType := sheet_row_cards.CardType{RowType: reflect.TypeOf(offerRow{})}
+	snap := &snapshot.Snapshot{}
+	for _, tab := range []struct {
+		name string
+		rows []offerRow
+	}{{"Products", products}, {"Services", services}} {
+		t := &snapshot.Tab{SheetID: "sheet", TabName: tab.name, CardType: cardType}
+		for _, row := range tab.rows {
+			t.Rows = append(t.Rows, row)
+		}
+		snap.Tabs = append(snap.Tabs, t)
+	}
+	return snap
+}
+
+func TestFind(t *testing.T) {
+	snap := testSnapshot(
+		[]offerRow{
+			offer("Acme Inc.", "https://acme.com", ""),
+			offer("Food Bank of Austin", "", ""),
+			offer("Hope Kitchen", "", "https://www.facebook.com/hopekitchen"),
+			offer("Zenith", "", "https://zenith.co.uk"),
+			offer("Paws Rescue", "", ""),
+		},
+		[]offerRow{
+			offer("ACME", "", ""),
+			offer("Acme Repairs", "", "https://repairs.acme.com"),
+			offer("Food Bank of Austn", "", ""),
+			offer("Grace Kitchen", "", "https://facebook.com/gracekitchen"),
+			offer("Zenith Ltd", "https://www.zenith.co.uk/help", ""),
+			offer("Paws Rescues", "", ""),
+			offer("Paws Rescue", "", ""),
+		},
+	)
+	type cluster struct {
+		key       string
+		titles    []string
+		reasons   []Reason
+		mergeable bool
+	}
+	want := []cluster{
+		// Acme Repairs shares a registrable domain with Acme Inc., whose
+		// name matches ACME
+		{"acme", []string{"Acme Inc.", "ACME", "Acme Repairs"}, []Reason{SameName, SameWebsite}, true},
+		// The similar name holds Paws Rescues in, so the cluster isn't merged
+		{"paws-rescue", []string{"Paws Rescue", "Paws Rescues", "Paws Rescue"}, []Reason{SimilarName, SameName}, false},
+		{"food-bank-of-austin", []string{"Food Bank of Austin", "Food Bank of Austn"}, []Reason{SimilarName}, false},
+		{"zenith", []string{"Zenith", "Zenith Ltd"}, []Reason{SameName}, true},
+		// Hope Kitchen and Grace Kitchen only share a Facebook link
+	}
+
+	var got []cluster
+	for _, c := range Find(snap) {
+		var titles []string
+		for _, member := range c.Members {
+			titles = append(titles, member.Title)
+		}
+		got = append(got, cluster{c.Key, titles, c.Reasons, c.Mergeable})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Find() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestFindMergeableSplit(t *testing.T) {
+	// Same names hold each pair together, but only a similar name joins
+	// the pairs, so the cluster could be two companies
+	snap := testSnapshot(
+		[]offerRow{offer("Harbor House", "", ""), offer("Harbor Mouse", "", "")},
+		[]offerRow{offer("Harbor House", "", ""), offer("Harbor Mouse", "https://harbormouse.org", ""), offer("Harbormouse", "", "harbormouse.org")},
+	)
+	clusters := Find(snap)
+	if len(clusters) != 1 {
+		t.Fatalf("Find() returned %d clusters, want 1", len(clusters))
+	}
+	if c := clusters[0]; c.Mergeable || len(c.Members) != 5 || !slices.Contains(c.Reasons, SimilarName) {
+		t.Errorf("Find() = %+v, want all five rows, not mergeable", c)
+	}
+
+	snap = testSnapshot(
+		[]offerRow{offer("Harbor Mouse", "", "")},
+		[]offerRow{offer("Harbor Mouse", "https://harbormouse.org", ""), offer("Harbormouse", "", "harbormouse.org")},
+	)
+	clusters = Find(snap)
+	if len(clusters) != 1 || !clusters[0].Mergeable || len(clusters[0].Members) != 3 {
+		t.Errorf("Find() = %+v, want one mergeable cluster of three rows", clusters)
+	}
+}
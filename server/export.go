@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"disaster/components"
+	"disaster/handlers"
+	"disaster/snapshot"
+	"disaster/staticsite"
+)
+
+// runExport renders the site from a fresh snapshot into a directory of static
+// files and, with -single, into one self-contained HTML file
+//
+//	disaster export -out dist -single mili-fit.html -base-url https://mili.fit
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	out := flags.String("out", "dist", "directory to write the site to, empty to skip")
+	single := flags.String("single", "", "also write the pages as one self-contained HTML file at this path")
+	baseURL := flags.String("base-url", os.Getenv("BASE_URL"), "URL of the live site, used for absolute links and for links to search")
+	flags.Parse(args)
+
+	if *baseURL != "" {
+		os.Setenv("BASE_URL", *baseURL)
+	} else {
+		logger.Println("export: no -base-url or BASE_URL, absolute links will point at example.com")
+	}
+
+	ctx := context.Background()
+
+	// Render from one snapshot that stays put, with every card of a tab on
+	// its page since there is no server to load more
+	snapshot.MaxAge = 24 * time.Hour
+	snap := snapshot.Refresh(ctx)
+	for key, err := range snap.Errors {
+		logger.Printf("export: %s left out: %v", key, err)
+	}
+	handlers.TabPageSize = 0
+
+	// Pages are found by following links from the home page; feeds and files
+	// nothing links to are seeded
+	seeds := []string{"/", "/sitemap.xml", "/robots.txt", "/feeds/new", "/static/img/og_preview.png"}
+	for _, category := range snap.Categories {
+		seeds = append(seeds, components.CategoryURL(category.Name), "/feeds/categories/"+url.PathEscape(category.Name))
+	}
+
+	site := staticsite.Crawl(ctx, recovery(logger)(router), *baseURL, seeds)
+	logger.Printf("export: rendered %d files", len(site.Files))
+
+	if *out != "" {
+		if err := site.WriteDir(*out); err != nil {
+			logger.Fatalf("export: writing %s: %v", *out, err)
+		}
+		logger.Printf("export: wrote %s", *out)
+	}
+
+	if *single != "" {
+		if err := os.MkdirAll(filepath.Dir(*single), 0o755); err != nil {
+			logger.Fatalf("export: %v", err)
+		}
+		f, err := os.Create(*single)
+		if err != nil {
+			logger.Fatalf("export: %v", err)
+		}
+		client := &http.Client{Timeout: 30 * time.Second}
+		if err := site.WriteSingle(ctx, f, client); err != nil {
+			f.Close()
+			logger.Fatalf("export: writing %s: %v", *single, err)
+		}
+		if err := f.Close(); err != nil {
+			logger.Fatalf("export: writing %s: %v", *single, err)
+		}
+		logger.Printf("export: wrote %s", *single)
+	}
+}
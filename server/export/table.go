@@ -0,0 +1,42 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// Table is a header row and data rows of plain text cells
+type Table struct {
+	Name   string // used as the worksheet name
+	Header []string
+	Rows   [][]string
+}
+
+// WriteCSV writes the table as CSV. Cells that a spreadsheet application
+// would evaluate as a formula are prefixed with a quote.
+func WriteCSV(w io.Writer, table Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(table.Header); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		escaped := make([]string, len(row))
+		for i, cell := range row {
+			escaped[i] = escapeFormula(cell)
+		}
+		if err := cw.Write(escaped); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// escapeFormula keeps a cell from being interpreted as a formula
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"=HYPERLINK(\"http://evil.example\",\"Click\")", "'=HYPERLINK(\"http://evil.example\",\"Click\")"},
+		{"+1 555 0100", "'+1 555 0100"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A9)", "'@SUM(A1:A9)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"Food Bank", "Food Bank"},
+		{"a=b", "a=b"},
+		{"'quoted", "'quoted"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := escapeFormula(test.cell); got != test.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", test.cell, got, test.want)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	table := Table{
+		Header: []string{"Company", "Phone"},
+		Rows: [][]string{
+			{"=cmd|' /C calc'!A0", "+1 555 0100"},
+			{"Acme, Inc.", "555 \"0101\""},
+		},
+	}
+	var b strings.Builder
+	if err := WriteCSV(&b, table); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("reading back %q: %v", b.String(), err)
+	}
+	want := [][]string{
+		{"Company", "Phone"},
+		{"'=cmd|' /C calc'!A0", "'+1 555 0100"},
+		{"Acme, Inc.", "555 \"0101\""},
+	}
+	if !slices.EqualFunc(records, want, slices.Equal) {
+		t.Errorf("WriteCSV wrote %q, want %q", records, want)
+	}
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// worksheet is the part of sheet1.xml WriteXLSX fills in
+type worksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R       string  `xml:"r,attr"`
+			T       string  `xml:"t,attr"`
+			Formula *string `xml:"f"`
+			Text    string  `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX returns the parsed worksheet and the workbook XML of a workbook
+func readXLSX(t *testing.T, data []byte) (worksheet, string) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name], err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sheet worksheet
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatalf("parsing worksheet: %v", err)
+	}
+	return sheet, string(parts["xl/workbook.xml"])
+}
+
+func TestWriteXLSX(t *testing.T) {
+	cells := []string{
+		"=HYPERLINK(\"http://evil.example\",\"Click\")",
+		"+1 555 0100",
+		"@SUM(A1:A9)",
+		"<b>&amp;</b>",
+		"bell\a",
+		"Food Bank",
+	}
+	var b bytes.Buffer
+	err := WriteXLSX(&b, Table{Name: "Offers: Food/Water", Header: []string{"Value"}, Rows: column(cells)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, workbook := readXLSX(t, b.Bytes())
+
+	if !bytes.Contains([]byte(workbook), []byte(`name="Offers- Food-Water"`)) {
+		t.Errorf("workbook.xml = %s, want the sheet named Offers- Food-Water", workbook)
+	}
+	if len(sheet.Rows) != len(cells)+1 {
+		t.Fatalf("got %d rows, want %d", len(sheet.Rows), len(cells)+1)
+	}
+	var got []string
+	for i, row := range sheet.Rows[1:] {
+		cell := row.Cells[0]
+		if cell.T != "inlineStr" || cell.Formula != nil {
+			t.Errorf("cell %s = %+v, want an inline string without a formula", cell.R, cell)
+		}
+		if want := "A" + strconv.Itoa(i+2); cell.R != want {
+			t.Errorf("cell reference %s, want %s", cell.R, want)
+		}
+		got = append(got, cell.Text)
+	}
+	// Inline strings are never evaluated, so formulas are kept as written
+	want := slices.Clone(cells)
+	want[4] = "bell�"
+	if !slices.Equal(got, want) {
+		t.Errorf("cells = %q, want %q", got, want)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{0, "A"}, {25, "Z"}, {26, "AA"}, {51, "AZ"}, {52, "BA"}, {701, "ZZ"}, {702, "AAA"},
+	}
+	for _, test := range tests {
+		if got := columnName(test.i); got != test.want {
+			t.Errorf("columnName(%d) = %q, want %q", test.i, got, test.want)
+		}
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Offers", "Offers"},
+		{"[Food]: Water/Ice?", "-Food-- Water-Ice-"},
+		{"", "Sheet1"},
+		{"A very long tab name that goes on and on", "A very long tab name that goes "},
+	}
+	for _, test := range tests {
+		if got := sheetName(test.name); got != test.want {
+			t.Errorf("sheetName(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// column returns a table column: a one-cell row for each cell
+func column(cells []string) [][]string {
+	rows := make([][]string, len(cells))
+	for i, cell := range cells {
+		rows[i] = []string{cell}
+	}
+	return rows
+}
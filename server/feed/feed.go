@@ -0,0 +1,215 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Feed is a list of entries that can be written as RSS, Atom or JSON Feed
+type Feed struct {
+	ID          string // unique, permanent identifier of the feed
+	Title       string
+	Description string
+	Link        string // page the feed describes
+	SelfLink    string // URL of the feed itself
+	Updated     time.Time
+	Entries     []Entry
+}
+
+// Entry is a single item of a feed
+type Entry struct {
+	ID         string
+	Title      string
+	Link       string
+	Published  time.Time
+	Content    string // plain text
+	Categories []string
+}
+
+// Formats maps format names to their content type and writer
+var Formats = map[string]struct {
+	ContentType string
+	Write       func(w io.Writer, f Feed) error
+}{
+	"rss":  {ContentType: "application/rss+xml; charset=utf-8", Write: WriteRSS},
+	"atom": {ContentType: "application/atom+xml; charset=utf-8", Write: WriteAtom},
+	"json": {ContentType: "application/feed+json; charset=utf-8", Write: WriteJSON},
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes the feed as RSS 2.0
+func WriteRSS(w io.Writer, f Feed) error {
+	doc := rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+	for _, e := range f.Entries {
+		item := rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        rssGUID{Value: e.ID},
+			Description: e.Content,
+			Categories:  e.Categories,
+		}
+		if !e.Published.IsZero() {
+			item.PubDate = e.Published.Format(time.RFC1123Z)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+	return writeXML(w, doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Content    atomText       `xml:"content"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// WriteAtom writes the feed as Atom 1.0
+func WriteAtom(w io.Writer, f Feed) error {
+	doc := atomFeed{
+		ID:      f.ID,
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Href: f.SelfLink},
+			{Rel: "alternate", Href: f.Link},
+		},
+	}
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			ID:        e.ID,
+			Title:     e.Title,
+			Updated:   e.Published.UTC().Format(time.RFC3339),
+			Published: e.Published.UTC().Format(time.RFC3339),
+			Content:   atomText{Type: "text", Value: e.Content},
+		}
+		if e.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: e.Link})
+		}
+		for _, category := range e.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return writeXML(w, doc)
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url,omitempty"`
+	FeedURL     string     `json:"feed_url,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url,omitempty"`
+	Title         string    `json:"title"`
+	ContentText   string    `json:"content_text"`
+	DatePublished time.Time `json:"date_published,omitzero"`
+	Tags          []string  `json:"tags,omitempty"`
+}
+
+// WriteJSON writes the feed as JSON Feed 1.1
+func WriteJSON(w io.Writer, f Feed) error {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.SelfLink,
+		Description: f.Description,
+		Items:       []jsonItem{},
+	}
+	for _, e := range f.Entries {
+		doc.Items = append(doc.Items, jsonItem{
+			ID:            e.ID,
+			URL:           e.Link,
+			Title:         e.Title,
+			ContentText:   e.Content,
+			DatePublished: e.Published,
+			Tags:          e.Categories,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// writeXML writes v as an indented XML document
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
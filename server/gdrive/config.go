@@ -1,15 +1,22 @@
 package gdrive
 
-// SheetConfig maps sheet IDs to their tab configurations
+// SheetConfig maps sheet IDs to their tab configurations.
+//
+// Each tab entry supports these keys:
+//   - Component: name of the registered card type used to render rows
+//   - StructuredDataRange: A1 range holding the header row and data rows
+//   - Facets: optional column headers rows can be filtered by
 var SheetConfig = map[string]map[string]interface{}{
 	"1L0dQpfj3c86mXRjADRrLshUCZrFzA3vcM_TfYxITjmc": {
 		"Company List - Free Product": map[string]interface{}{
 			"Component":           "FreeProductCard",
 			"StructuredDataRange": "A6:G",
+			"Facets":              []string{"Category", "Type"},
 		},
 		"Company List - Discount Codes": map[string]interface{}{
 			"Component":           "DiscountCard",
 			"StructuredDataRange": "A6:F",
+			"Facets":              []string{"Category"},
 		},
 		"Company List - Free Product Pick-ups": map[string]interface{}{
 			"Component":           "PickupCard",
@@ -18,6 +25,7 @@ var SheetConfig = map[string]map[string]interface{}{
 		"Company List - Free Services": map[string]interface{}{
 			"Component":           "ServiceCard",
 			"StructuredDataRange": "A6:F",
+			"Facets":              []string{"Category"},
 		},
 	},
 }
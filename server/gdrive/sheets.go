@@ -2,6 +2,7 @@ package gdrive
 
 import (
 	"context"
+	"disaster/apperr"
 	"disaster/model"
 	"fmt"
 	"log"
@@ -26,7 +27,7 @@ func GetCategories(ctx context.Context) ([]model.Category, error) {
 	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Do()
 	if err != nil {
 		log.Printf("Unable to retrieve data from sheet: %v", err)
-		return nil, fmt.Errorf("failed to get sheet data: %w", err)
+		return nil, apperr.Wrap(apperr.Unavailable, err, "Google Sheets is unavailable right now")
 	}
 
 	if len(resp.Values) == 0 {
@@ -40,8 +41,8 @@ func GetCategories(ctx context.Context) ([]model.Category, error) {
 	// Iterate through the rows
 	for _, row := range resp.Values {
 		if len(row) >= 3 { // Make sure we have enough columns
-			categoryName := row[0].(string) // Category is in the 1st column since we're get C through E
-			versionStr := row[2].(string)   // Version is in the 3rd column
+			categoryName := cellText(row[0]) // Category is in the 1st column since we're get C through E
+			versionStr := cellText(row[2])   // Version is in the 3rd column
 			version, err := strconv.ParseFloat(versionStr, 32)
 			if err != nil {
 				log.Printf("Warning: invalid version number %q for category %q: %v", versionStr, categoryName, err)
@@ -60,8 +61,8 @@ func GetCategories(ctx context.Context) ([]model.Category, error) {
 	return categories, nil
 }
 
-// GetResourcesByCategory retrieves all resources for a specific category from the Google Sheet
-func GetResourcesByCategory(ctx context.Context, category string) ([]model.Resource, error) {
+// GetResources retrieves every resource from the Google Sheet
+func GetResources(ctx context.Context) ([]model.Resource, error) {
 	spreadsheetID := "1DX0_eUz1QRe0xWdnVAhIOYYsp7lpOUsmMg74QIjwDac"
 	readRange := "Sheet1!A3:F" // Include all columns
 
@@ -74,7 +75,7 @@ func GetResourcesByCategory(ctx context.Context, category string) ([]model.Resou
 	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Do()
 	if err != nil {
 		log.Printf("Unable to retrieve data from sheet: %v", err)
-		return nil, fmt.Errorf("failed to get sheet data: %w", err)
+		return nil, apperr.Wrap(apperr.Unavailable, err, "Google Sheets is unavailable right now")
 	}
 
 	if len(resp.Values) == 0 {
@@ -86,22 +87,52 @@ func GetResourcesByCategory(ctx context.Context, category string) ([]model.Resou
 	// Iterate through the rows
 	for _, row := range resp.Values {
 		if len(row) >= 4 { // Make sure we have enough columns
-			rowCategory := row[2].(string) // Category is in the third column
-			if rowCategory == category {
-				resource := model.Resource{
-					Name:        row[0].(string), // Name is in the first column
-					Description: row[1].(string), // Description is in the second column
-					Category:    rowCategory,
-					Link:        row[3].(string), // Link is in the fourth column
-				}
-				resources = append(resources, resource)
+			resource := model.Resource{
+				Name:        cellText(row[0]), // Name is in the first column
+				Description: cellText(row[1]), // Description is in the second column
+				Category:    cellText(row[2]), // Category is in the third column
+				Link:        cellText(row[3]), // Link is in the fourth column
 			}
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources, nil
+}
+
+// GetResourcesByCategory retrieves all resources for a specific category from the Google Sheet
+func GetResourcesByCategory(ctx context.Context, category string) ([]model.Resource, error) {
+	all, err := GetResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []model.Resource
+	for _, resource := range all {
+		if resource.Category == category {
+			resources = append(resources, resource)
 		}
 	}
 
 	return resources, nil
 }
 
+// cellText returns the text of a cell value from the Sheets API, which is
+// usually but not always a string
+func cellText(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}:
+		if text, ok := v["text"].(string); ok {
+			return text
+		}
+	}
+	return fmt.Sprint(cell)
+}
+
 // ExtractGoogleDocID extracts the Google Doc ID from a URL
 func ExtractGoogleDocID(url string) string {
 	// Common patterns for Google Sheets URLs:
@@ -138,7 +169,7 @@ func GetSpreadsheetInfo(ctx context.Context, spreadsheetID string) ([]TabInfo, e
 	// Check if spreadsheet exists in config
 	tabConfig, exists := SheetConfig[spreadsheetID]
 	if !exists {
-		return nil, fmt.Errorf("no configuration found for spreadsheet ID: %s", spreadsheetID)
+		return nil, apperr.New(apperr.NotConfigured, "No configuration found for spreadsheet %s", spreadsheetID)
 	}
 
 	srv, err := sheets.NewService(ctx, option.WithScopes(sheets.SpreadsheetsReadonlyScope))
@@ -149,7 +180,7 @@ func GetSpreadsheetInfo(ctx context.Context, spreadsheetID string) ([]TabInfo, e
 	// Get spreadsheet metadata
 	spreadsheet, err := srv.Spreadsheets.Get(spreadsheetID).Do()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get spreadsheet metadata: %w", err)
+		return nil, apperr.Wrap(apperr.Unavailable, err, "Google Sheets is unavailable right now")
 	}
 
 	// Build tab info list
@@ -181,11 +212,11 @@ func GetSheetData(ctx context.Context, spreadsheetID, tabName, dataRange string)
 	resp, err := srv.Spreadsheets.Get(spreadsheetID).Ranges(readRange).IncludeGridData(true).Do()
 	if err != nil {
 		log.Printf("Unable to retrieve data from sheet: %v", err)
-		return nil, fmt.Errorf("failed to get sheet data: %w", err)
+		return nil, apperr.Wrap(apperr.Unavailable, err, "Google Sheets is unavailable right now")
 	}
 
 	if len(resp.Sheets) == 0 || len(resp.Sheets[0].Data) == 0 || len(resp.Sheets[0].Data[0].RowData) == 0 {
-		return nil, fmt.Errorf("no data found in range %s", readRange)
+		return nil, apperr.New(apperr.NotConfigured, "No data found in range %s", readRange)
 	}
 
 	// Convert the response to our desired format
@@ -232,7 +263,7 @@ func GetSheetDataFromConfig(ctx context.Context, spreadsheetID string) (map[stri
 	// Check if spreadsheet exists in config
 	tabConfig, exists := SheetConfig[spreadsheetID]
 	if !exists {
-		return nil, fmt.Errorf("no configuration found for spreadsheet ID: %s", spreadsheetID)
+		return nil, apperr.New(apperr.NotConfigured, "No configuration found for spreadsheet %s", spreadsheetID)
 	}
 
 	result := make(map[string][][]interface{})
@@ -252,3 +283,74 @@ func GetSheetDataFromConfig(ctx context.Context, spreadsheetID string) (map[stri
 
 	return result, nil
 }
+
+// AppendRow adds a row below the data of a configured tab. values holds cell
+// text by column header and links, also by header, turns cells into
+// hyperlinks. Cells are entered as if typed into the sheet, with text that
+// would be read as a formula kept as text.
+func AppendRow(ctx context.Context, spreadsheetID, tabName, dataRange string, values, links map[string]string) error {
+	srv, err := sheets.NewService(ctx, option.WithScopes(sheets.SpreadsheetsScope))
+	if err != nil {
+		return fmt.Errorf("failed to create sheets service: %w", err)
+	}
+
+	// Order the cells by the tab's header row
+	headerRange := a1Range(tabName, headerRowRange(dataRange))
+	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, headerRange).Context(ctx).Do()
+	if err != nil {
+		return apperr.Wrap(apperr.Unavailable, err, "Google Sheets is unavailable right now")
+	}
+	if len(resp.Values) == 0 {
+		return apperr.New(apperr.NotConfigured, "No header row found in range %s", headerRange)
+	}
+
+	var row []interface{}
+	for _, header := range resp.Values[0] {
+		col := cellText(header)
+		text := enteredText(values[col])
+		if link := links[col]; link != "" {
+			text = fmt.Sprintf(`=HYPERLINK("%s", "%s")`, formulaString(link), formulaString(values[col]))
+		}
+		row = append(row, text)
+	}
+
+	appendRange := a1Range(tabName, dataRange)
+	_, err = srv.Spreadsheets.Values.Append(spreadsheetID, appendRange, &sheets.ValueRange{Values: [][]interface{}{row}}).
+		ValueInputOption("USER_ENTERED").
+		InsertDataOption("INSERT_ROWS").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return apperr.Wrap(apperr.Unavailable, err, "Google Sheets is unavailable right now")
+	}
+	return nil
+}
+
+// a1Range returns a range of a tab in A1 notation, quoting the tab name
+func a1Range(tabName, cells string) string {
+	return "'" + strings.ReplaceAll(tabName, "'", "''") + "'!" + cells
+}
+
+// headerRowRange returns the first row of an A1 range, e.g. "A6:G6" for "A6:G"
+func headerRowRange(dataRange string) string {
+	start, end, ok := strings.Cut(dataRange, ":")
+	if !ok {
+		return dataRange
+	}
+	row := strings.TrimLeft(start, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	return start + ":" + strings.TrimRight(end, "0123456789") + row
+}
+
+// enteredText keeps text that Sheets would read as a formula as text when
+// entered as if typed
+func enteredText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@'", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// formulaString escapes s for a string literal in a formula
+func formulaString(s string) string {
+	return strings.ReplaceAll(s, `"`, `""`)
+}
@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"disaster/store"
+)
+
+// cacheFile holds geocoding results by normalized address
+const cacheFile = "geocode_cache.json"
+
+// retryMisses is how long an address that couldn't be placed is remembered
+// before the geocoder is asked again
+const retryMisses = 7 * 24 * time.Hour
+
+// cacheEntry is a remembered geocoding result
+type cacheEntry struct {
+	Point *Point    `json:"point,omitempty"` // nil when the address couldn't be placed
+	At    time.Time `json:"at"`
+}
+
+// Cache remembers the results of another geocoder in the store, so each
+// address is only looked up once, across restarts
+type Cache struct {
+	Geocoder Geocoder
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCache returns a persistent cache in front of a geocoder
+func NewCache(geocoder Geocoder) *Cache {
+	return &Cache{Geocoder: geocoder}
+}
+
+// Geocode returns the remembered result for an address, looking it up when
+// there is none. Errors other than ErrNotFound are not remembered.
+func (c *Cache) Geocode(ctx context.Context, address string) (Point, error) {
+	key := strings.Join(strings.Fields(strings.ToLower(address)), " ")
+
+	c.mu.Lock()
+	c.load()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		if entry.Point != nil {
+			return *entry.Point, nil
+		}
+		if time.Since(entry.At) < retryMisses {
+			return Point{}, ErrNotFound
+		}
+	}
+
+	point, err := c.Geocoder.Geocode(ctx, address)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Point{}, err
+	}
+	entry = cacheEntry{At: time.Now()}
+	if err == nil {
+		entry.Point = &point
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	if saveErr := store.Save(cacheFile, c.entries); saveErr != nil {
+		log.Printf("Geo: error saving geocode cache: %v", saveErr)
+	}
+	c.mu.Unlock()
+	return point, err
+}
+
+// load reads the cache from the store once. It must be called with mu held.
+func (c *Cache) load() {
+	if c.entries != nil {
+		return
+	}
+	c.entries = make(map[string]cacheEntry)
+	if err := store.Load(cacheFile, &c.entries); err != nil {
+		log.Printf("Geo: error loading geocode cache: %v", err)
+	}
+}
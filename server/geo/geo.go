@@ -0,0 +1,65 @@
+// Package geo places addresses and ZIP codes on the map, for sorting listings
+// by distance and matching them against service areas. Lookups go through a
+// pluggable Geocoder; the default works offline from a table of ZIP code
+// centroids.
+package geo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"regexp"
+)
+
+// ErrNotFound is returned by a Geocoder that can't place an address
+var ErrNotFound = errors.New("location not found")
+
+// Point is a place on the map
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Geocoder finds where an address is. It returns ErrNotFound when it can't
+// place the address.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (Point, error)
+}
+
+// ZIPs is the table of ZIP code centroids and areas behind Default
+var ZIPs = &ZIPTable{}
+
+// Default is the geocoder used for listings and visitors' ZIP codes
+var Default Geocoder = NewCache(ZIPs)
+
+// earthRadiusMiles is the mean radius of the Earth
+const earthRadiusMiles = 3958.8
+
+// Miles returns the great-circle distance between two points in miles
+func Miles(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat, dLon := lat2-lat1, radians(b.Lon-a.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+var zipPattern = regexp.MustCompile(`\b(\d{5})(?:-\d{4})?\b`)
+
+// ExtractZIP returns the last ZIP code in an address, which is where US
+// addresses put it
+func ExtractZIP(address string) (string, bool) {
+	matches := zipPattern.FindAllStringSubmatch(address, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	return matches[len(matches)-1][1], true
+}
+
+// IsZIP reports whether s is a five-digit ZIP code
+func IsZIP(s string) bool {
+	return len(s) == 5 && zipPattern.MatchString(s)
+}
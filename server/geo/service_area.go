@@ -0,0 +1,88 @@
+package geo
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ServiceArea is where a resource is offered, as written in a "Service Area"
+// column: a list of entries separated by commas or semicolons, each one of
+//
+//	Nationwide (or Everywhere, Anywhere, Online)
+//	90210           a ZIP code
+//	902xx           every ZIP code starting 902
+//	25 miles of 90210
+//
+// Entries it can't read, such as city or state names, are ignored.
+type ServiceArea struct {
+	Everywhere bool
+	ZIPs       []string
+	Prefixes   []string
+	Radii      []Radius
+}
+
+// Radius is a circle around a point
+type Radius struct {
+	Center Point
+	Miles  float64
+}
+
+var (
+	prefixPattern = regexp.MustCompile(`^(\d{3})(?:xx|\*)$`)
+	radiusPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(?:mi|miles?)\s+(?:of|from|around)\s+(.+)$`)
+)
+
+// ParseServiceArea reads a service area, using the geocoder to place the
+// centers of radius entries
+func ParseServiceArea(ctx context.Context, geocoder Geocoder, text string) ServiceArea {
+	var area ServiceArea
+	for _, entry := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case entry == "nationwide" || entry == "everywhere" || entry == "anywhere" || entry == "online":
+			area.Everywhere = true
+		case IsZIP(entry):
+			area.ZIPs = append(area.ZIPs, entry)
+		case prefixPattern.MatchString(entry):
+			area.Prefixes = append(area.Prefixes, prefixPattern.FindStringSubmatch(entry)[1])
+		case radiusPattern.MatchString(entry):
+			match := radiusPattern.FindStringSubmatch(entry)
+			miles, _ := strconv.ParseFloat(match[1], 64)
+			if center, err := geocoder.Geocode(ctx, match[2]); err == nil {
+				area.Radii = append(area.Radii, Radius{Center: center, Miles: miles})
+			}
+		}
+	}
+	return area
+}
+
+// Known reports whether any entry of the area could be read
+func (a ServiceArea) Known() bool {
+	return a.Everywhere || len(a.ZIPs) > 0 || len(a.Prefixes) > 0 || len(a.Radii) > 0
+}
+
+// Covers reports whether the area includes a ZIP code at the given point
+func (a ServiceArea) Covers(zip string, point Point) bool {
+	if a.Everywhere {
+		return true
+	}
+	for _, covered := range a.ZIPs {
+		if covered == zip {
+			return true
+		}
+	}
+	for _, prefix := range a.Prefixes {
+		if strings.HasPrefix(zip, prefix) {
+			return true
+		}
+	}
+	for _, radius := range a.Radii {
+		if Miles(radius.Center, point) <= radius.Miles {
+			return true
+		}
+	}
+	return false
+}
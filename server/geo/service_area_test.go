@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// fakeGeocoder places the addresses in its map
+type fakeGeocoder map[string]Point
+
+func (g fakeGeocoder) Geocode(ctx context.Context, address string) (Point, error) {
+	if p, ok := g[address]; ok {
+		return p, nil
+	}
+	return Point{}, ErrNotFound
+}
+
+var (
+	austin  = Point{30.2672, -97.7431}
+	dallas  = Point{32.7767, -96.7970}
+	sanJose = Point{37.3382, -121.8863}
+)
+
+func TestParseServiceArea(t *testing.T) {
+	geocoder := fakeGeocoder{"78701": austin, "austin, tx": austin}
+	tests := []struct {
+		text string
+		want ServiceArea
+	}{
+		{"Nationwide", ServiceArea{Everywhere: true}},
+		{"online", ServiceArea{Everywhere: true}},
+		{"78701, 78702; 78703\n78704", ServiceArea{ZIPs: []string{"78701", "78702", "78703", "78704"}}},
+		{"787xx, 750*", ServiceArea{Prefixes: []string{"787", "750"}}},
+		{"25 miles of 78701", ServiceArea{Radii: []Radius{{austin, 25}}}},
+		{"10.5 mi around Austin, TX", ServiceArea{}}, // the comma splits the entry
+		{"2 mile from 78701; 5 Miles Of 78701", ServiceArea{Radii: []Radius{{austin, 2}, {austin, 5}}}},
+		// Entries it can't read or place are ignored
+		{"Travis County, 50 miles of Nowhere, 7870, 78701-1234", ServiceArea{}},
+		{"Travis County, 78701", ServiceArea{ZIPs: []string{"78701"}}},
+		{"", ServiceArea{}},
+	}
+	for _, test := range tests {
+		got := ParseServiceArea(context.Background(), geocoder, test.text)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseServiceArea(%q) = %+v, want %+v", test.text, got, test.want)
+		}
+		if got.Known() != test.want.Known() {
+			t.Errorf("ParseServiceArea(%q).Known() = %v, want %v", test.text, got.Known(), test.want.Known())
+		}
+	}
+}
+
+func TestServiceAreaCovers(t *testing.T) {
+	tests := []struct {
+		name  string
+		area  ServiceArea
+		zip   string
+		point Point
+		want  bool
+	}{
+		{"everywhere", ServiceArea{Everywhere: true}, "95113", sanJose, true},
+		{"listed ZIP", ServiceArea{ZIPs: []string{"78701", "75201"}}, "75201", dallas, true},
+		{"unlisted ZIP", ServiceArea{ZIPs: []string{"78701"}}, "78702", austin, false},
+		{"prefix", ServiceArea{Prefixes: []string{"787"}}, "78745", austin, true},
+		{"other prefix", ServiceArea{Prefixes: []string{"787"}}, "75201", dallas, false},
+		{"inside radius", ServiceArea{Radii: []Radius{{austin, 200}}}, "75201", dallas, true},
+		{"outside radius", ServiceArea{Radii: []Radius{{austin, 150}}}, "75201", dallas, false},
+		{"any entry", ServiceArea{ZIPs: []string{"95113"}, Radii: []Radius{{austin, 1}}}, "78701", austin, true},
+		{"nothing known", ServiceArea{}, "78701", austin, false},
+	}
+	for _, test := range tests {
+		if got := test.area.Covers(test.zip, test.point); got != test.want {
+			t.Errorf("%s: Covers(%q) = %v, want %v", test.name, test.zip, got, test.want)
+		}
+	}
+}
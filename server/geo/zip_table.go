@@ -0,0 +1,146 @@
+package geo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// zipsCSV is a starter table of ZIP code centroids for the cities the
+// directory covers, as "zip,lat,lon" rows
+//
+//go:embed zips.csv
+var zipsCSV []byte
+
+// ZIPTable geocodes addresses by their ZIP code, offline. It reads the table
+// in GEO_ZIP_TABLE when set, either as "zip,lat,lon" CSV or as the Census
+// Bureau's tab-separated ZCTA gazetteer file, and the embedded starter table
+// otherwise.
+type ZIPTable struct {
+	once      sync.Once
+	centroids map[string]Point
+	radii     map[string]float64 // miles, for ZIP codes with a land area
+}
+
+// DefaultZIPRadius is the radius in miles given to ZIP codes whose land area
+// isn't in the table, about that of a suburban ZIP code
+const DefaultZIPRadius = 3.0
+
+// ZIPArea is roughly where a ZIP code is: a circle around its centroid with
+// the ZIP code's land area
+type ZIPArea struct {
+	Center Point
+	Radius float64 // miles
+}
+
+// Area returns the rough area of a five-digit ZIP code
+func (t *ZIPTable) Area(zip string) (ZIPArea, bool) {
+	t.once.Do(t.load)
+	center, ok := t.centroids[zip]
+	if !ok {
+		return ZIPArea{}, false
+	}
+	radius, ok := t.radii[zip]
+	if !ok {
+		radius = DefaultZIPRadius
+	}
+	return ZIPArea{Center: center, Radius: radius}, true
+}
+
+// Geocode returns the centroid of the address's ZIP code
+func (t *ZIPTable) Geocode(ctx context.Context, address string) (Point, error) {
+	t.once.Do(t.load)
+	zip, ok := ExtractZIP(address)
+	if !ok {
+		return Point{}, ErrNotFound
+	}
+	point, ok := t.centroids[zip]
+	if !ok {
+		return Point{}, ErrNotFound
+	}
+	return point, nil
+}
+
+// load reads the ZIP table, falling back to the embedded one when the
+// configured table can't be read
+func (t *ZIPTable) load() {
+	if path := os.Getenv("GEO_ZIP_TABLE"); path != "" {
+		f, err := os.Open(path)
+		if err == nil {
+			defer f.Close()
+			t.centroids, t.radii, err = parseZIPTable(f)
+		}
+		if err == nil {
+			log.Printf("Geo: loaded %d ZIP codes from %s", len(t.centroids), path)
+			return
+		}
+		log.Printf("Geo: error reading ZIP table %s, using the built-in one: %v", path, err)
+	}
+	centroids, radii, err := parseZIPTable(bytes.NewReader(zipsCSV))
+	if err != nil {
+		log.Printf("Geo: error reading built-in ZIP table: %v", err)
+	}
+	t.centroids, t.radii = centroids, radii
+}
+
+// parseZIPTable reads "zip,lat,lon" CSV or a ZCTA gazetteer file, which has
+// GEOID, INTPTLAT and INTPTLONG columns separated by tabs. An optional land
+// area column, ALAND_SQMI or area_sqmi, gives each ZIP code the radius of a
+// circle of that area.
+func parseZIPTable(r io.Reader) (map[string]Point, map[string]float64, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, nil, fmt.Errorf("empty ZIP table")
+	}
+	separator := ","
+	if strings.Contains(scanner.Text(), "\t") {
+		separator = "\t"
+	}
+	zipCol, latCol, lonCol, areaCol := -1, -1, -1, -1
+	for i, header := range strings.Split(scanner.Text(), separator) {
+		switch strings.ToLower(strings.TrimSpace(header)) {
+		case "zip", "geoid":
+			zipCol = i
+		case "lat", "intptlat":
+			latCol = i
+		case "lon", "intptlong":
+			lonCol = i
+		case "aland_sqmi", "area_sqmi":
+			areaCol = i
+		}
+	}
+	if zipCol < 0 || latCol < 0 || lonCol < 0 {
+		return nil, nil, fmt.Errorf("ZIP table needs zip, lat and lon columns")
+	}
+
+	centroids := make(map[string]Point)
+	radii := make(map[string]float64)
+	for line := 2; scanner.Scan(); line++ {
+		cols := strings.Split(scanner.Text(), separator)
+		if len(cols) <= max(zipCol, latCol, lonCol) {
+			continue
+		}
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(cols[latCol]), 64)
+		lon, lonErr := strconv.ParseFloat(strings.TrimSpace(cols[lonCol]), 64)
+		if latErr != nil || lonErr != nil {
+			return nil, nil, fmt.Errorf("line %d: invalid coordinates", line)
+		}
+		zip := strings.TrimSpace(cols[zipCol])
+		centroids[zip] = Point{Lat: lat, Lon: lon}
+		if areaCol >= 0 && areaCol < len(cols) {
+			if area, err := strconv.ParseFloat(strings.TrimSpace(cols[areaCol]), 64); err == nil && area > 0 {
+				radii[zip] = math.Sqrt(area / math.Pi)
+			}
+		}
+	}
+	return centroids, radii, scanner.Err()
+}
@@ -5,6 +5,8 @@ go 1.24.2
 require (
 	github.com/a-h/templ v0.3.857
 	github.com/jritsema/gotoolbox v0.10.0
+	golang.org/x/net v0.39.0
+	golang.org/x/text v0.24.0
 	google.golang.org/api v0.228.0
 )
 
@@ -25,10 +27,8 @@ require (
 	go.opentelemetry.io/otel/metric v1.35.0 // indirect
 	go.opentelemetry.io/otel/trace v1.35.0 // indirect
 	golang.org/x/crypto v0.37.0 // indirect
-	golang.org/x/net v0.39.0 // indirect
 	golang.org/x/oauth2 v0.29.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
-	golang.org/x/text v0.24.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250409194420-de1ac958c67a // indirect
 	google.golang.org/grpc v1.71.1 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"disaster/auth"
+	"disaster/components"
+	"disaster/gdrive"
+	"disaster/moderation"
+	"disaster/pages"
+	"disaster/reports"
+	"disaster/snapshot"
+)
+
+// HandleAdminDashboard shows the configured tabs, the snapshot and the
+// moderation queues. It reports the snapshot as it is, without refreshing it.
+func HandleAdminDashboard(w http.ResponseWriter, r *http.Request) {
+	snap, refreshing := snapshot.State()
+	dashboard := components.AdminDashboard{
+		Refreshing:         refreshing,
+		CanRefresh:         canRefresh(r),
+		MaxAge:             snapshot.MaxAge,
+		PendingSuggestions: len(moderation.Submissions(moderation.Pending)),
+	}
+	if snap != nil {
+		dashboard.TakenAt = snap.TakenAt
+		dashboard.Categories = len(snap.Categories)
+		dashboard.Resources = len(snap.Resources)
+		for _, source := range []string{"categories", "resources"} {
+			if err := snap.Errors[source]; err != nil {
+				dashboard.SourceErrors = append(dashboard.SourceErrors, "Error reading "+source+": "+err.Error())
+			}
+		}
+	}
+	dashboard.Tabs = adminTabs(snap)
+
+	threshold := reports.Threshold()
+	for _, row := range reports.Ranked() {
+		dashboard.ReportedCards++
+		if row.Count() >= threshold {
+			dashboard.FlaggedCards++
+		}
+	}
+
+	dashboard.StaleAfter = snapshot.StaleAfter
+	dashboard.StaleCards = adminStaleCards(snap)
+	if snap != nil {
+		now := time.Now()
+		for _, tab := range snap.Tabs {
+			for _, row := range tab.Rows {
+				if tab.Expired(row, now) {
+					dashboard.ExpiredCards++
+				}
+			}
+		}
+	}
+
+	dashboard.DuplicateClusters = len(currentDuplicates(snap).clusters)
+
+	config, err := json.MarshalIndent(gdrive.SheetConfig, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding sheet config: %v", err)
+	}
+	dashboard.SheetConfig = string(config)
+
+	meta := components.PageMeta{Title: "Admin - mili.fit", NoIndex: true}
+	if err := pages.Admin(meta, dashboard).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering admin dashboard: %v", err)
+	}
+}
+
+// canRefresh reports whether the signed-in user may take a new snapshot
+func canRefresh(r *http.Request) bool {
+	user, ok := auth.UserFrom(r.Context())
+	return ok && user.Role.Allows(auth.Admin)
+}
+
+// HandleAdminRefresh takes a new snapshot right away
+func HandleAdminRefresh(w http.ResponseWriter, r *http.Request) {
+	snap := snapshot.Refresh(r.Context())
+	log.Printf("Snapshot refreshed from the admin dashboard: %d tabs, %d errors", len(snap.Tabs), len(snap.Errors))
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// adminTabs lists every tab in gdrive.SheetConfig with how it loaded into
+// snap, which may be nil
+func adminTabs(snap *snapshot.Snapshot) []components.AdminTab {
+	sheetIDs := make([]string, 0, len(gdrive.SheetConfig))
+	for sheetID := range gdrive.SheetConfig {
+		sheetIDs = append(sheetIDs, sheetID)
+	}
+	sort.Strings(sheetIDs)
+
+	var tabs []components.AdminTab
+	for _, sheetID := range sheetIDs {
+		tabNames := make([]string, 0, len(gdrive.SheetConfig[sheetID]))
+		for tabName := range gdrive.SheetConfig[sheetID] {
+			tabNames = append(tabNames, tabName)
+		}
+		sort.Strings(tabNames)
+
+		for _, tabName := range tabNames {
+			view := components.AdminTab{SheetID: sheetID, SheetTitle: sheetID, TabName: tabName}
+			if settings, err := snapshot.Settings(sheetID, tabName); err != nil {
+				view.Error = err.Error()
+			} else {
+				view.Component = settings.Component
+				view.DataRange = settings.DataRange
+			}
+			if snap != nil {
+				view.SheetTitle = sheetTitle(snap, sheetID)
+				if tab, ok := snap.Tab(sheetID, tabName); ok {
+					view.URL = components.TabURL(sheetCategory(snap, sheetID), sheetID, tabName)
+					view.FetchedAt = tab.FetchedAt
+					view.Rows = len(tab.Rows)
+					view.ParseErrors = tab.ParseErrors
+				}
+				// A tab that failed to load may still be served from an
+				// earlier snapshot, as of its FetchedAt
+				if err := snap.Errors[sheetID+"/"+tabName]; err != nil {
+					view.Error = err.Error()
+				}
+			}
+			tabs = append(tabs, view)
+		}
+	}
+	return tabs
+}
+
+// adminStaleCards lists the rows marked stale, least recently updated first.
+// Cards link to their page when they are in snap, which may be nil.
+func adminStaleCards(snap *snapshot.Snapshot) []components.AdminStaleCard {
+	var cards []components.AdminStaleCard
+	for id, row := range snapshot.StaleRows() {
+		card := components.AdminStaleCard{Title: row.Title, TabName: row.TabName, UpdatedAt: row.UpdatedAt}
+		if snap != nil {
+			if _, _, ok := findRow(snap, row.SheetID, row.TabName, id); ok {
+				card.URL = components.RowURL(sheetCategory(snap, row.SheetID), row.SheetID, row.TabName, id)
+			}
+		}
+		cards = append(cards, card)
+	}
+	sort.Slice(cards, func(i, j int) bool { return cards[i].UpdatedAt.Before(cards[j].UpdatedAt) })
+	return cards
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"disaster/apperr"
+	"disaster/auth"
+)
+
+// RequireRole only lets requests from users signed in with at least the given
+// role through, with the user in the request context. Signed-out visitors
+// opening a page are sent to sign in first. Cross-site form posts are refused
+// so other sites can't act on a signed-in user's behalf.
+func RequireRole(role auth.Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.FromRequest(r)
+		if !ok {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+				return
+			}
+			WriteError(w, r, apperr.New(apperr.Unauthorized, "You need to sign in to see this page."))
+			return
+		}
+		if !user.Role.Allows(role) {
+			WriteError(w, r, apperr.New(apperr.Forbidden, "You don't have access to this page."))
+			return
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" {
+				WriteError(w, r, apperr.New(apperr.Forbidden, "You don't have access to this page."))
+				return
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"disaster/apperr"
+	"disaster/gdrive"
+	"disaster/model"
+	"disaster/snapshot"
+)
+
+// APIResource is a resource from the master sheet as returned by the v1 API.
+// SheetID is set when the resource links to a Google Sheet whose tabs can be
+// read through the API.
+type APIResource struct {
+	model.Resource
+	SheetID string `json:"sheetId,omitempty"`
+}
+
+// APIRow is a single parsed row of a sheet tab
+type APIRow struct {
+	ID     string `json:"id"`
+	Fields any    `json:"fields"`
+}
+
+// APITabRows is the response body of the v1 tab rows endpoint
+type APITabRows struct {
+	SheetID    string                  `json:"sheetId"`
+	Tab        string                  `json:"tab"`
+	Component  string                  `json:"component"`
+	Total      int                     `json:"total"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+	Facets     map[string][]FacetCount `json:"facets,omitempty"`
+	Rows       []APIRow                `json:"rows"`
+}
+
+// HandleAPICategories returns every category from the master sheet as JSON,
+// as of the current snapshot
+func HandleAPICategories(w http.ResponseWriter, r *http.Request) {
+	snap := snapshot.Current(r.Context())
+	if err, failed := snap.Errors["categories"]; failed && len(snap.Categories) == 0 {
+		WriteError(w, r, err)
+		return
+	}
+	categories := snap.Categories
+	if categories == nil {
+		categories = []model.Category{}
+	}
+
+	writeJSON(w, categories)
+}
+
+// HandleAPIResources returns the resources of a category as JSON, as of the
+// current snapshot
+func HandleAPIResources(w http.ResponseWriter, r *http.Request) {
+	category := r.URL.Query().Get("category")
+	if category == "" {
+		WriteError(w, r, apperr.New(apperr.BadInput, "Category is required"))
+		return
+	}
+
+	snap := snapshot.Current(r.Context())
+	if err, failed := snap.Errors["resources"]; failed && len(snap.Resources) == 0 {
+		WriteError(w, r, err)
+		return
+	}
+
+	result := make([]APIResource, 0)
+	for _, resource := range snap.Resources {
+		if resource.Category != category {
+			continue
+		}
+		result = append(result, APIResource{
+			Resource: resource,
+			SheetID:  gdrive.ExtractGoogleDocID(resource.Link),
+		})
+	}
+
+	writeJSON(w, result)
+}
+
+// HandleAPISheetTabs returns the tabs of a configured sheet as JSON
+func HandleAPISheetTabs(w http.ResponseWriter, r *http.Request) {
+	sheetID := r.PathValue("id")
+	if _, exists := gdrive.SheetConfig[sheetID]; !exists {
+		WriteError(w, r, apperr.New(apperr.NotConfigured, "Sheet not configured"))
+		return
+	}
+
+	tabs, err := gdrive.GetSpreadsheetInfo(r.Context(), sheetID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if tabs == nil {
+		tabs = []gdrive.TabInfo{}
+	}
+
+	writeJSON(w, tabs)
+}
+
+// HandleAPITabRows returns the parsed rows of a configured tab as JSON, from
+// the current snapshot so row IDs match the site's card permalinks. It
+// accepts the same filter, sort and page parameters as the tab view; all
+// matching rows are returned unless a limit is given.
+func HandleAPITabRows(w http.ResponseWriter, r *http.Request) {
+	tab, err := snapshotTab(r.Context(), r.PathValue("id"), r.PathValue("tab"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	query, err := parseTabQuery(r.Context(), r.URL.Query(), tab)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	matched := query.Filter(tab)
+	page, next := query.Page(matched)
+
+	var nextCursor string
+	if next >= 0 {
+		nextCursor = strconv.Itoa(next)
+	}
+
+	rows := make([]APIRow, 0, len(page))
+	for _, row := range page {
+		rows = append(rows, APIRow{
+			ID:     tab.RowID(row),
+			Fields: row,
+		})
+	}
+
+	writeJSON(w, APITabRows{
+		SheetID:    tab.SheetID,
+		Tab:        tab.TabName,
+		Component:  tab.Component,
+		Total:      len(matched),
+		NextCursor: nextCursor,
+		Facets:     query.FacetCounts(tab),
+		Rows:       rows,
+	})
+}
+
+// apiTabURL returns the v1 API path of a configured tab
+func apiTabURL(sheetID, tabName string) string {
+	return "/api/v1/sheets/" + url.PathEscape(sheetID) + "/tabs/" + url.PathEscape(tabName)
+}
+
+// writeJSON writes v as a JSON response body
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/components/sheet_row_cards"
+	"disaster/ratelimit"
+	"disaster/snapshot"
+	"disaster/votes"
+)
+
+// voterCookie identifies a visitor's votes so they can't vote twice from
+// different addresses
+const voterCookie = "voter"
+
+// voteLimiter caps how many votes a client can cast across all cards
+var voteLimiter = ratelimit.New(60, time.Hour)
+
+// HandleConfirm records a visitor's vote on whether a card worked for them
+func HandleConfirm(w http.ResponseWriter, r *http.Request) {
+	category, sheetID, tabName, rowID := r.PathValue("category"), r.PathValue("sheet"), r.PathValue("tab"), r.PathValue("row")
+
+	if !voteLimiter.Allow(clientIP(r)) {
+		err := fmt.Errorf("too many votes from %s", clientIP(r))
+		WriteError(w, r, apperr.Wrap(apperr.RateLimited, err, "You're doing that too often. Please try again later."))
+		return
+	}
+
+	tab, _, err := lookupRow(snapshot.Current(r.Context()), sheetID, tabName, rowID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if !sheet_row_cards.IsConfirmable(tab.CardType.RowType) {
+		WriteError(w, r, apperr.New(apperr.NotConfigured, "These cards can't be confirmed"))
+		return
+	}
+
+	var worked bool
+	switch r.PostFormValue("worked") {
+	case "yes":
+		worked = true
+	case "no":
+		worked = false
+	default:
+		WriteError(w, r, apperr.New(apperr.BadInput, "Say whether it worked for you."))
+		return
+	}
+
+	if err := votes.Cast(rowID, clientIP(r), voterID(w, r), worked); err != nil {
+		WriteError(w, r, fmt.Errorf("saving vote on row %s: %w", rowID, err))
+		return
+	}
+	log.Printf("Row %s of %s/%s voted worked=%t", rowID, sheetID, tabName, worked)
+
+	rowURL := components.RowURL(category, sheetID, tabName, rowID)
+	if r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-Boosted") != "true" {
+		if err := sheet_row_cards.ConfirmationBox(rowURL+"/confirm", votes.Summary(rowID)).Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering confirmations: %v", err)
+		}
+		return
+	}
+	http.Redirect(w, r, rowURL, http.StatusSeeOther)
+}
+
+// voterID returns the visitor's voter cookie ID, setting a new one if they
+// have none
+func voterID(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(voterCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		log.Printf("Error generating voter ID: %v", err)
+		return ""
+	}
+	value := hex.EncodeToString(id)
+	http.SetCookie(w, &http.Cookie{
+		Name:     voterCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return value
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/duplicates"
+	"disaster/i18n"
+	"disaster/pages"
+	"disaster/snapshot"
+)
+
+// duplicateIndex is the suspected duplicates of a snapshot, and the
+// mergeable ones by cluster key and by the ID of every row in them
+type duplicateIndex struct {
+	clusters []duplicates.Cluster
+	byKey    map[string]int
+	byRow    map[string]int
+}
+
+var (
+	duplicatesMu   sync.Mutex
+	duplicatesSnap *snapshot.Snapshot
+	duplicatesIdx  *duplicateIndex
+)
+
+// currentDuplicates returns the suspected duplicates of snap, which may be
+// nil, finding them again when the snapshot has been refreshed
+func currentDuplicates(snap *snapshot.Snapshot) *duplicateIndex {
+	if snap == nil {
+		return &duplicateIndex{}
+	}
+
+	duplicatesMu.Lock()
+	defer duplicatesMu.Unlock()
+	if snap != duplicatesSnap {
+		idx := &duplicateIndex{
+			clusters: duplicates.Find(snap),
+			byKey:    make(map[string]int),
+			byRow:    make(map[string]int),
+		}
+		for i, cluster := range idx.clusters {
+			if !cluster.Mergeable {
+				continue
+			}
+			idx.byKey[cluster.Key] = i
+			for _, member := range cluster.Members {
+				idx.byRow[member.RowID] = i
+			}
+		}
+		duplicatesIdx = idx
+		duplicatesSnap = snap
+	}
+	return duplicatesIdx
+}
+
+// companyOffers returns the page of every offer from a row's company and how
+// many unexpired ones there are, when the row is one of a cluster of
+// duplicates with another unexpired offer. It looks rows up in the snapshot as
+// it is, without refreshing it.
+func companyOffers(rowID string) (string, int, bool) {
+	snap, _ := snapshot.State()
+	idx := currentDuplicates(snap)
+	i, ok := idx.byRow[rowID]
+	if !ok {
+		return "", 0, false
+	}
+	cluster := idx.clusters[i]
+	offers := len(unexpiredMembers(snap, cluster, time.Now()))
+	if offers < 2 {
+		return "", 0, false
+	}
+	return components.CompanyURL(cluster.Key), offers, true
+}
+
+// unexpiredMembers returns the rows of a cluster that aren't past their Valid
+// Until date at now
+func unexpiredMembers(snap *snapshot.Snapshot, cluster duplicates.Cluster, now time.Time) []duplicates.Member {
+	var members []duplicates.Member
+	for _, member := range cluster.Members {
+		if tab, ok := snap.Tab(member.SheetID, member.TabName); ok && !tab.Expired(member.Row, now) {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// HandleDuplicates lists the clusters of cards that look like the same
+// company, largest first. It reports the snapshot as it is, without
+// refreshing it.
+func HandleDuplicates(w http.ResponseWriter, r *http.Request) {
+	snap, _ := snapshot.State()
+	var clusters []components.DuplicateCluster
+	for _, cluster := range currentDuplicates(snap).clusters {
+		view := components.DuplicateCluster{Name: cluster.Name, Unmerged: duplicates.Merge && !cluster.Mergeable}
+		if duplicates.Merge && cluster.Mergeable {
+			view.URL = components.CompanyURL(cluster.Key)
+		}
+		for _, reason := range cluster.Reasons {
+			view.Reasons = append(view.Reasons, string(reason))
+		}
+		for _, member := range cluster.Members {
+			view.Members = append(view.Members, components.DuplicateMember{
+				Title:      member.Title,
+				SheetTitle: sheetTitle(snap, member.SheetID),
+				TabName:    member.TabName,
+				URL:        components.RowURL(sheetCategory(snap, member.SheetID), member.SheetID, member.TabName, member.RowID),
+				Link:       member.Link,
+			})
+		}
+		clusters = append(clusters, view)
+	}
+
+	meta := components.PageMeta{Title: "Suspected duplicates - mili.fit", NoIndex: true}
+	if err := pages.Duplicates(meta, clusters, duplicates.Merge).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering duplicates page: %v", err)
+	}
+}
+
+// HandleCompanyPage merges the cards that look like the same company into
+// one, showing every unexpired offer from it grouped by tab. It is only
+// served when duplicates.Merge is on.
+func HandleCompanyPage(w http.ResponseWriter, r *http.Request) {
+	if !duplicates.Merge {
+		WriteError(w, r, apperr.New(apperr.NotConfigured, "This page isn't set up on mili.fit."))
+		return
+	}
+	ctx := r.Context()
+	snap := snapshot.Current(ctx)
+	idx := currentDuplicates(snap)
+	i, ok := idx.byKey[r.PathValue("key")]
+	var members []duplicates.Member
+	if ok {
+		members = unexpiredMembers(snap, idx.clusters[i], time.Now())
+	}
+	if len(members) == 0 {
+		WriteError(w, r, apperr.New(apperr.NotFound, "This company's offers are no longer listed together"))
+		return
+	}
+	cluster := idx.clusters[i]
+
+	var (
+		groups   []components.SearchResultGroup
+		websites []string
+	)
+	groupIndex := make(map[string]int)
+	seenWebsites := make(map[string]bool)
+	for _, member := range members {
+		if host := duplicates.NormalizeLink(member.Link); host != "" && !seenWebsites[host] {
+			seenWebsites[host] = true
+			websites = append(websites, websiteURL(member.Link))
+		}
+
+		key := member.SheetID + "/" + member.TabName
+		g, ok := groupIndex[key]
+		if !ok {
+			group, ok := newSearchResultGroup(ctx, snap, "", key)
+			if !ok {
+				continue
+			}
+			group.MoreURL = ""
+			group.Actions.Offers = nil
+			g = len(groups)
+			groupIndex[key] = g
+			groups = append(groups, group)
+		}
+		groups[g].Rows = append(groups[g].Rows, member.Row)
+	}
+
+	meta := pageMeta(r, cluster.Name+" - mili.fit", i18n.T(ctx, "Every offer from %s listed on mili.fit", cluster.Name))
+	if err := pages.Company(meta, cluster.Name, len(members), websites, groups).Render(ctx, w); err != nil {
+		log.Printf("Error rendering company page: %v", err)
+	}
+}
+
+// websiteURL returns a link from a card as an absolute URL, adding the https
+// scheme sheets often leave out
+func websiteURL(link string) string {
+	link = strings.TrimSpace(link)
+	if !strings.Contains(link, "://") {
+		return "https://" + link
+	}
+	return link
+}
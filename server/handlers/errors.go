@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/pages"
+)
+
+// Problem is an RFC 9457 problem details response body
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// WriteError logs err and reports it in the form the client asked for: an
+// ErrorFragment for htmx requests, an error page for browser navigation and
+// form posts (including hx-boost) and problem+json for everything else,
+// including every /api/v1 request.
+// Internal errors are reported without their details.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	kind := apperr.KindOf(err)
+	requestID := w.Header().Get("X-Request-Id")
+	log.Printf("Error handling %s %s (request %s, %s): %v", r.Method, r.URL.Path, requestID, kind, err)
+
+	message := errorMessage(w, err)
+
+	boosted := r.Header.Get("HX-Boosted") == "true"
+	switch {
+	case r.Header.Get("HX-Request") == "true" && !boosted:
+		// Keep the target element so later requests can still find it; the
+		// fragment's retry button swaps the content back in.
+		w.Header().Set("HX-Reswap", "innerHTML")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(kind.Status())
+		if err := components.ErrorFragment(message).Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering error fragment: %v", err)
+		}
+	case !strings.HasPrefix(r.URL.Path, "/api/") && (boosted || strings.Contains(r.Header.Get("Accept"), "text/html")):
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(kind.Status())
+		if err := pages.Error(message, r.URL.RequestURI()).Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering error page: %v", err)
+		}
+	default:
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(kind.Status())
+		err := json.NewEncoder(w).Encode(Problem{
+			Type:      tagURI(baseURL(r), "problems/"+kind.Slug()),
+			Title:     kind.Title(),
+			Status:    kind.Status(),
+			Detail:    message.Message,
+			Instance:  r.URL.RequestURI(),
+			RequestID: requestID,
+		})
+		if err != nil {
+			log.Printf("Error encoding problem response: %v", err)
+		}
+	}
+}
+
+// errorMessage describes err for display to a visitor
+func errorMessage(w http.ResponseWriter, err error) components.ErrorMessage {
+	kind := apperr.KindOf(err)
+	return components.ErrorMessage{
+		Title:     kind.Title(),
+		Message:   apperr.Message(err),
+		RequestID: w.Header().Get("X-Request-Id"),
+		Retry:     kind == apperr.Unavailable || kind == apperr.Internal,
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/components/sheet_row_cards"
+	"disaster/feed"
+	"disaster/snapshot"
+)
+
+// feedEntryLimit is the number of newest rows included in a feed
+const feedEntryLimit = 50
+
+// feedRow is a row of a snapshot tab with the time it was added
+type feedRow struct {
+	Tab     *snapshot.Tab
+	Row     any
+	AddedAt time.Time
+}
+
+// HandleWhatsNewFeed serves the newest rows of every configured tab
+func HandleWhatsNewFeed(w http.ResponseWriter, r *http.Request) {
+	writeFeed(w, r, "What's new on mili.fit", "Newly added offers from every list", "/",
+		func(tab *snapshot.Tab, row any) bool { return true })
+}
+
+// HandleTabFeed serves the newest rows of a configured tab
+func HandleTabFeed(w http.ResponseWriter, r *http.Request) {
+	sheetID, tabName := r.PathValue("id"), r.PathValue("tab")
+	snap := snapshot.Current(r.Context())
+	if _, ok := snap.Tab(sheetID, tabName); !ok {
+		WriteError(w, r, snapshot.ErrTabNotConfigured)
+		return
+	}
+
+	writeFeed(w, r, tabName+" - mili.fit", "Newly added offers in "+tabName, tabViewURL(sheetCategory(snap, sheetID), sheetID, tabName, tabQuery{}),
+		func(tab *snapshot.Tab, row any) bool { return tab.SheetID == sheetID && tab.TabName == tabName })
+}
+
+// HandleCategoryFeed serves the newest rows of every tab whose sheet is in a
+// category of the master sheet, the same tabs the category page links to
+func HandleCategoryFeed(w http.ResponseWriter, r *http.Request) {
+	category := r.PathValue("category")
+	snap := snapshot.Current(r.Context())
+	if !hasCategory(snap, category) {
+		WriteError(w, r, apperr.New(apperr.NotFound, "There are no resources in %q", category))
+		return
+	}
+
+	inCategory := make(map[string]bool)
+	for _, tab := range snap.Tabs {
+		inCategory[tab.SheetID] = sheetCategory(snap, tab.SheetID) == category
+	}
+
+	writeFeed(w, r, category+" - mili.fit", "Newly added offers in "+category, components.CategoryURL(category),
+		func(tab *snapshot.Tab, row any) bool { return inCategory[tab.SheetID] })
+}
+
+// writeFeed writes the newest rows of the current snapshot accepted by include
+// in the format given by the format query parameter, leaving out rows past
+// their Valid Until date
+func writeFeed(w http.ResponseWriter, r *http.Request, title, description, link string, include func(tab *snapshot.Tab, row any) bool) {
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "rss"
+	}
+	format, ok := feed.Formats[formatName]
+	if !ok {
+		WriteError(w, r, apperr.New(apperr.BadInput, "Unsupported feed format %q", formatName))
+		return
+	}
+
+	snap := snapshot.Current(r.Context())
+
+	var rows []feedRow
+	now := time.Now()
+	for _, tab := range snap.Tabs {
+		for _, row := range tab.Rows {
+			if !include(tab, row) || tab.Expired(row, now) {
+				continue
+			}
+			addedAt := tab.AddedAt(row)
+			if addedAt.IsZero() {
+				continue
+			}
+			rows = append(rows, feedRow{Tab: tab, Row: row, AddedAt: addedAt})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].AddedAt.After(rows[j].AddedAt) })
+	if len(rows) > feedEntryLimit {
+		rows = rows[:feedEntryLimit]
+	}
+
+	base := baseURL(r)
+	f := feed.Feed{
+		ID:          tagURI(base, r.URL.Path),
+		Title:       title,
+		Description: description,
+		Link:        base + link,
+		SelfLink:    base + r.URL.RequestURI(),
+		Updated:     snap.TakenAt,
+	}
+	if len(rows) > 0 {
+		f.Updated = rows[0].AddedAt
+	}
+
+	for _, fr := range rows {
+		content, err := sheet_row_cards.CardText(r.Context(), fr.Tab.CardType.RenderFunc, fr.Row)
+		if err != nil {
+			log.Printf("Error rendering feed entry for tab %s: %v", fr.Tab.TabName, err)
+			continue
+		}
+
+		company := fr.Tab.Title(fr.Row)
+		entry := feed.Entry{
+			ID:        tagURI(base, "row/"+fr.Tab.RowID(fr.Row)),
+			Title:     company + " - " + fr.Tab.TabName,
+			Link:      base + components.RowURL(sheetCategory(snap, fr.Tab.SheetID), fr.Tab.SheetID, fr.Tab.TabName, fr.Tab.RowID(fr.Row)),
+			Published: fr.AddedAt,
+			Content:   content,
+		}
+		if category := rowCategory(fr.Tab, fr.Row); category != "" {
+			entry.Categories = append(entry.Categories, category)
+		}
+		f.Entries = append(f.Entries, entry)
+	}
+
+	w.Header().Set("Content-Type", format.ContentType)
+	if err := format.Write(w, f); err != nil {
+		log.Printf("Error writing %s feed: %v", formatName, err)
+	}
+}
+
+// rowCategory returns the text of a row's Category column
+func rowCategory(tab *snapshot.Tab, row any) string {
+	if field, ok := sheet_row_cards.FieldByCol(tab.CardType.RowType, "Category"); ok {
+		return sheet_row_cards.FieldText(row, field)
+	}
+	return ""
+}
+
+// baseURL returns the scheme and host the site is served from, from the
+// BASE_URL environment variable or the request
+func baseURL(r *http.Request) string {
+	if base := os.Getenv("BASE_URL"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+// tagURI returns a permanent tag: URI (RFC 4151) for a path on the site
+func tagURI(base, path string) string {
+	host := base
+	if u, err := url.Parse(base); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	return "tag:" + host + ",2025:" + strings.TrimPrefix(path, "/")
+}
+
+// tabFeedURL returns the feed URL of a tab
+func tabFeedURL(sheetID, tabName string) string {
+	return "/feeds/sheets/" + url.PathEscape(sheetID) + "/tabs/" + url.PathEscape(tabName)
+}
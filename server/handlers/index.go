@@ -1,12 +1,59 @@
 package handlers
 
 import (
+	"disaster/components"
 	"disaster/pages"
+	"disaster/snapshot"
+	"disaster/zones"
+	"log"
 	"net/http"
 )
 
+// Index renders the home page directory with the resources of the category
+// in the category query parameter. htmx requests from the category grid get
+// only the directory.
 func Index(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	component := pages.Index()
-	component.Render(ctx, w)
+
+	// Tab views used to be shared as /?sheet=<id>&tab=<name>
+	values := r.URL.Query()
+	if sheetID, tabName := values.Get("sheet"), values.Get("tab"); sheetID != "" && tabName != "" {
+		values.Del("sheet")
+		values.Del("tab")
+		target := tabViewURL(sheetCategory(snapshot.Current(ctx), sheetID), sheetID, tabName, tabQuery{})
+		if len(values) > 0 {
+			target += "?" + values.Encode()
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	snap := snapshot.Current(ctx)
+	directory := components.DirectoryProps{
+		Categories: snap.Categories,
+		Category:   values.Get("category"),
+	}
+
+	// The page still renders without the master sheet; the directory shows
+	// what went wrong in place of the categories
+	if err, failed := snap.Errors["categories"]; failed && len(snap.Categories) == 0 {
+		log.Printf("Error loading categories for home page: %v", err)
+		message := errorMessage(w, err)
+		message.Retry = false
+		directory.Error = &message
+	}
+
+	for _, resource := range snap.Resources {
+		if directory.Category != "" && resource.Category == directory.Category {
+			directory.Resources = append(directory.Resources, resource)
+		}
+	}
+
+	component := pages.Index(pageMeta(r, "mili.fit", ""), directory, len(zones.ZoneConfig) > 0)
+	if r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-Boosted") != "true" {
+		component = components.Directory(directory)
+	}
+	if err := component.Render(ctx, w); err != nil {
+		log.Printf("Error rendering home page: %v", err)
+	}
 }
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"disaster/apperr"
+	"disaster/auth"
+	"disaster/components"
+	"disaster/mail"
+	"disaster/pages"
+	"disaster/ratelimit"
+)
+
+// OIDC is the optional OpenID Connect provider users can sign in with; main
+// sets it from the environment
+var OIDC *auth.OIDCProvider
+
+// loginLimiter caps how many sign-in links a client can ask for
+var loginLimiter = ratelimit.New(10, time.Hour)
+
+// HandleLoginPage renders the sign-in form
+func HandleLoginPage(w http.ResponseWriter, r *http.Request) {
+	renderLoginPage(w, r, "")
+}
+
+// HandleLogin emails a sign-in link to the address entered, if it belongs to
+// a user. The page looks the same either way, so it doesn't tell who can sign
+// in.
+func HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		WriteError(w, r, apperr.Wrap(apperr.BadInput, err, "The form couldn't be read."))
+		return
+	}
+	base, ok := emailBaseURL()
+	if !ok {
+		WriteError(w, r, apperr.New(apperr.NotConfigured, "Sign-in links aren't set up on mili.fit. Set BASE_URL to send them."))
+		return
+	}
+	email := strings.TrimSpace(r.PostForm.Get("email"))
+	if email == "" {
+		WriteError(w, r, apperr.New(apperr.BadInput, "Enter a valid email address."))
+		return
+	}
+	if !loginLimiter.Allow(clientIP(r)) {
+		err := fmt.Errorf("too many sign-in links asked for from %s", clientIP(r))
+		WriteError(w, r, apperr.Wrap(apperr.RateLimited, err, "You're doing that too often. Please try again later."))
+		return
+	}
+
+	if _, ok := auth.RoleOf(email); ok {
+		token, err := auth.MagicLinkToken(email)
+		if err != nil {
+			WriteError(w, r, fmt.Errorf("signing sign-in link: %w", err))
+			return
+		}
+		link := base + "/login/link?" + url.Values{"token": {token}, "next": {safeNext(r.PostForm.Get("next"))}}.Encode()
+		err = Mailer.Send(r.Context(), mail.Message{
+			To:      email,
+			Subject: "Sign in to mili.fit",
+			Body: fmt.Sprintf("Open this link to sign in to mili.fit. It works once, for the next %d minutes:\n\n%s\n\n"+
+				"If you didn't ask to sign in, you can ignore this email.", int(auth.MagicLinkLength.Minutes()), link),
+		})
+		if err != nil {
+			WriteError(w, r, fmt.Errorf("sending sign-in link: %w", err))
+			return
+		}
+		log.Printf("Sent sign-in link to a user")
+	} else {
+		log.Printf("Sign-in link asked for by an address not in AUTH_USERS")
+	}
+	renderLoginPage(w, r, fmt.Sprintf("If that address can sign in, we've emailed it a link. The link works once, for %d minutes.", int(auth.MagicLinkLength.Minutes())))
+}
+
+// emailBaseURL returns BASE_URL, the only base emailed links and the OIDC
+// redirect URL are built on. The request's Host and X-Forwarded-Proto headers
+// are up to the client, so a sign-in link built from them could send its
+// token to any host.
+func emailBaseURL() (string, bool) {
+	base := os.Getenv("BASE_URL")
+	return strings.TrimSuffix(base, "/"), base != ""
+}
+
+// HandleLoginLinkPage asks the user to confirm signing in from an emailed
+// link, so link scanners opening it don't use it up
+func HandleLoginLinkPage(w http.ResponseWriter, r *http.Request) {
+	meta := components.PageMeta{Title: "Sign in - mili.fit", NoIndex: true}
+	if err := pages.LoginLink(meta, r.URL.RequestURI()).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering sign-in link page: %v", err)
+	}
+}
+
+// HandleLoginLink signs in the user an emailed link was sent to
+func HandleLoginLink(w http.ResponseWriter, r *http.Request) {
+	email, err := auth.RedeemMagicLink(r.URL.Query().Get("token"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	signIn(w, r, email, r.URL.Query().Get("next"))
+}
+
+// HandleOIDCLogin sends the browser to the OIDC provider to sign in
+func HandleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	redirectURL, ok := oidcRedirectURL()
+	if OIDC == nil || !ok {
+		WriteError(w, r, apperr.New(apperr.NotConfigured, "This page isn't set up on mili.fit."))
+		return
+	}
+	target, err := OIDC.StartLogin(w, r, redirectURL, safeNext(r.URL.Query().Get("next")))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// HandleOIDCCallback signs in the user the OIDC provider sent back
+func HandleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	redirectURL, ok := oidcRedirectURL()
+	if OIDC == nil || !ok {
+		WriteError(w, r, apperr.New(apperr.NotConfigured, "This page isn't set up on mili.fit."))
+		return
+	}
+	email, next, err := OIDC.FinishLogin(w, r, redirectURL)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	signIn(w, r, email, next)
+}
+
+// HandleLogout signs the user out
+func HandleLogout(w http.ResponseWriter, r *http.Request) {
+	auth.EndSession(w, r)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// signIn starts a session for a user in AUTH_USERS and sends them on to next
+func signIn(w http.ResponseWriter, r *http.Request, email, next string) {
+	role, ok := auth.RoleOf(email)
+	if !ok {
+		WriteError(w, r, apperr.New(apperr.Forbidden, "You don't have access to this page."))
+		return
+	}
+	if err := auth.StartSession(w, r, email); err != nil {
+		WriteError(w, r, fmt.Errorf("starting session: %w", err))
+		return
+	}
+	log.Printf("Signed in a user with role %s", role)
+	http.Redirect(w, r, safeNext(next), http.StatusSeeOther)
+}
+
+// renderLoginPage renders the sign-in form, or a message in its place
+func renderLoginPage(w http.ResponseWriter, r *http.Request, message string) {
+	var oidcName string
+	if _, ok := oidcRedirectURL(); OIDC != nil && ok {
+		oidcName = OIDC.Name
+	}
+	next := safeNext(r.FormValue("next"))
+	meta := components.PageMeta{Title: "Sign in - mili.fit", NoIndex: true}
+	if err := pages.Login(meta, next, oidcName, message).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering sign-in page: %v", err)
+	}
+}
+
+// oidcRedirectURL returns where the OIDC provider sends users back to, which
+// must be registered with the provider. Like emailed links it is only built on
+// BASE_URL, and is false without it.
+func oidcRedirectURL() (string, bool) {
+	base, ok := emailBaseURL()
+	return base + "/login/oidc/callback", ok
+}
+
+// safeNext returns the page to go to after signing in, which must be on this
+// site, defaulting to the admin dashboard
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/admin"
+	}
+	return next
+}
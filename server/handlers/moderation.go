@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"disaster/components"
+	"disaster/components/sheet_row_cards"
+	"disaster/gdrive"
+	"disaster/moderation"
+	"disaster/pages"
+	"disaster/snapshot"
+)
+
+// HandleModeration lists the suggestions waiting for review
+func HandleModeration(w http.ResponseWriter, r *http.Request) {
+	var pending []components.SubmissionView
+	for _, sub := range moderation.Submissions(moderation.Pending) {
+		view := components.SubmissionView{
+			Submission: sub,
+			TabURL:     components.TabURL(sub.Category, sub.SheetID, sub.TabName),
+		}
+		if settings, err := snapshot.Settings(sub.SheetID, sub.TabName); err == nil {
+			for _, field := range sheet_row_cards.RowFields(settings.CardType.RowType) {
+				view.Columns = append(view.Columns, field.Col)
+			}
+		}
+		pending = append(pending, view)
+	}
+
+	meta := components.PageMeta{Title: "Moderation - mili.fit", NoIndex: true}
+	if err := pages.Moderation(meta, pending).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering moderation page: %v", err)
+	}
+}
+
+// HandleApproveSubmission adds a suggestion to its sheet and takes it off the
+// queue. The submission is claimed first, so a double click or a second
+// moderator can't add it twice; it goes back in the queue only if adding it
+// fails.
+func HandleApproveSubmission(w http.ResponseWriter, r *http.Request) {
+	sub, err := moderation.Claim(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	settings, err := snapshot.Settings(sub.SheetID, sub.TabName)
+	if err != nil {
+		moderation.Release(sub.ID)
+		WriteError(w, r, err)
+		return
+	}
+
+	values := make(map[string]string, len(sub.Values))
+	for col, value := range sub.Values {
+		values[col] = value
+	}
+	sheet_row_cards.FillDates(settings.CardType.RowType, values, time.Now())
+
+	// A moderator leaving the page mustn't cut the append short after the
+	// row is written, which would put the submission back in the queue
+	if err := gdrive.AppendRow(context.WithoutCancel(r.Context()), sub.SheetID, sub.TabName, settings.DataRange, values, sub.Links); err != nil {
+		moderation.Release(sub.ID)
+		WriteError(w, r, fmt.Errorf("adding submission %s to %s/%s: %w", sub.ID, sub.SheetID, sub.TabName, err))
+		return
+	}
+	if err := moderation.Review(sub.ID, moderation.Approved); err != nil {
+		WriteError(w, r, fmt.Errorf("submission %s was added to %s/%s but not marked approved: %w", sub.ID, sub.SheetID, sub.TabName, err))
+		return
+	}
+	log.Printf("Approved submission %s for %s/%s", sub.ID, sub.SheetID, sub.TabName)
+	http.Redirect(w, r, "/admin/moderation", http.StatusSeeOther)
+}
+
+// HandleRejectSubmission takes a suggestion off the queue without adding it
+func HandleRejectSubmission(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := moderation.Review(id, moderation.Rejected); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	log.Printf("Rejected submission %s", id)
+	http.Redirect(w, r, "/admin/moderation", http.StatusSeeOther)
+}
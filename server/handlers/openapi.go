@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"disaster/components/sheet_row_cards"
+	"disaster/gdrive"
+	"disaster/model"
+	"disaster/openapi"
+)
+
+// APIOperation is an endpoint of the v1 JSON API. The APIOperations table
+// drives both route registration and the published OpenAPI document.
+type APIOperation struct {
+	Method     string
+	Path       string
+	ID         string
+	Summary    string
+	Parameters []openapi.Parameter
+	// Response is the type of the JSON response body. Operations with a
+	// non-JSON body list their content types in ContentTypes instead.
+	Response     reflect.Type
+	ContentTypes []string
+	Handler      http.HandlerFunc
+}
+
+// tabParameters are the path parameters of a tab
+var tabParameters = []openapi.Parameter{
+	{Name: "id", In: "path", Required: true, Description: "Google Sheet ID", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "tab", In: "path", Required: true, Description: "Tab name", Schema: &openapi.Schema{Type: "string"}},
+}
+
+// tabQueryParameters are the filter, sort and page parameters of a tab
+var tabQueryParameters = []openapi.Parameter{
+	{Name: "q", In: "query", Description: "Free-text filter; every word must appear in one of the row's fields", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "sort", In: "query", Description: "Field key to sort by, prefixed with - for descending. DiscountCard and PickupCard tabs also sort by -confirmed, most recently confirmed working first, and PickupCard tabs by distance from near. Facet columns configured for the tab are also accepted as parameters named by their field key.", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "near", In: "query", Description: "Five-digit ZIP code. Rows whose Service Area column doesn't cover it are left out, and PickupCard tabs are sorted nearest first unless another sort is given.", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "open", In: "query", Description: "now to keep only the rows whose Hours column says they are open at the time of the request", Schema: &openapi.Schema{Type: "string", Enum: []string{"now"}}},
+	{Name: "view", In: "query", Description: "expired for the archive of rows past the date in their Valid Until or Expires column, which are otherwise left out", Schema: &openapi.Schema{Type: "string", Enum: []string{"expired"}}},
+	{Name: "cursor", In: "query", Description: "nextCursor of the previous page", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "limit", In: "query", Description: "Page size, at most 100", Schema: &openapi.Schema{Type: "integer"}},
+}
+
+// APIOperations lists every v1 API endpoint
+var APIOperations = []APIOperation{
+	{
+		Method:   http.MethodGet,
+		Path:     "/api/v1/categories",
+		ID:       "listCategories",
+		Summary:  "Categories from the master sheet",
+		Response: reflect.TypeOf([]model.Category{}),
+		Handler:  HandleAPICategories,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/api/v1/resources",
+		ID:      "listResources",
+		Summary: "Resources in a category",
+		Parameters: []openapi.Parameter{
+			{Name: "category", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		},
+		Response: reflect.TypeOf([]APIResource{}),
+		Handler:  HandleAPIResources,
+	},
+	{
+		Method:     http.MethodGet,
+		Path:       "/api/v1/sheets/{id}/tabs",
+		ID:         "listSheetTabs",
+		Summary:    "Tabs of a configured sheet",
+		Parameters: tabParameters[:1],
+		Response:   reflect.TypeOf([]gdrive.TabInfo{}),
+		Handler:    HandleAPISheetTabs,
+	},
+	{
+		Method:     http.MethodGet,
+		Path:       "/api/v1/sheets/{id}/tabs/{tab}/rows",
+		ID:         "listTabRows",
+		Summary:    "Parsed rows of a configured tab",
+		Parameters: append(append([]openapi.Parameter{}, tabParameters...), tabQueryParameters...),
+		Response:   reflect.TypeOf(APITabRows{}),
+		Handler:    HandleAPITabRows,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/api/v1/sheets/{id}/tabs/{tab}/export",
+		ID:      "exportTabRows",
+		Summary: "Download of the filtered rows of a configured tab",
+		Parameters: append(append(append([]openapi.Parameter{}, tabParameters...), tabQueryParameters[:5]...),
+			openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []string{"csv", "xlsx"}}}),
+		ContentTypes: []string{exportFormats["csv"].ContentType, exportFormats["xlsx"].ContentType},
+		Handler:      HandleTabExport,
+	},
+}
+
+// APIDocument builds the OpenAPI document of the v1 API from APIOperations
+// and the registered card row types
+func APIDocument() *openapi.Document {
+	gen := openapi.NewGenerator()
+	gen.Define("Resource", reflect.TypeOf(APIResource{}))
+	gen.Define("Row", reflect.TypeOf(APIRow{}))
+	gen.Define("TabRows", reflect.TypeOf(APITabRows{}))
+	problem := gen.Define("Problem", reflect.TypeOf(Problem{}))
+
+	// A row's fields are one of the registered row types, as named by the
+	// component of the tab
+	cardNames := sheet_row_cards.CardTypeNames()
+	var rowSchemas []*openapi.Schema
+	for _, name := range cardNames {
+		cardType, _ := sheet_row_cards.GetCardType(name)
+		rowSchemas = append(rowSchemas, gen.Define(cardType.RowType.Name(), cardType.RowType))
+	}
+	if fields, ok := gen.Schemas["Row"].Properties.Get("fields"); ok {
+		*fields = openapi.Schema{OneOf: rowSchemas}
+	}
+	if component, ok := gen.Schemas["TabRows"].Properties.Get("component"); ok {
+		component.Enum = cardNames
+	}
+
+	doc := &openapi.Document{
+		OpenAPI: "3.1.0",
+		Info: openapi.Info{
+			Title:   "mili.fit API",
+			Version: "1",
+		},
+		Paths: make(map[string]openapi.PathItem),
+	}
+
+	for _, op := range APIOperations {
+		operation := &openapi.Operation{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Parameters:  op.Parameters,
+			Responses: map[string]openapi.Response{
+				"default": {
+					Description: "Error",
+					Content:     map[string]openapi.MediaType{"application/problem+json": {Schema: problem}},
+				},
+			},
+		}
+
+		ok := openapi.Response{Description: "OK", Content: make(map[string]openapi.MediaType)}
+		if op.Response != nil {
+			ok.Content["application/json"] = openapi.MediaType{Schema: gen.SchemaFor(op.Response)}
+		}
+		for _, contentType := range op.ContentTypes {
+			ok.Content[contentType] = openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary"}}
+		}
+		operation.Responses["200"] = ok
+
+		if doc.Paths[op.Path] == nil {
+			doc.Paths[op.Path] = make(openapi.PathItem)
+		}
+		doc.Paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	doc.Components.Schemas = gen.Schemas
+	return doc
+}
+
+// HandleOpenAPI serves the OpenAPI document of the v1 API
+func HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, APIDocument())
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/i18n"
+	"disaster/pages"
+	"disaster/plans"
+	"disaster/ratelimit"
+	"disaster/snapshot"
+)
+
+// sharePlanLimiter caps how many plans a client can share, since each one is
+// stored on the server
+var sharePlanLimiter = ratelimit.New(10, time.Hour)
+
+// HandlePlanPage renders the visitor's plan. The cards saved on their device
+// are posted back by the page to HandlePlanEntries.
+func HandlePlanPage(w http.ResponseWriter, r *http.Request) {
+	meta := pageMeta(r, i18n.T(r.Context(), "My plan")+" - mili.fit", "")
+	meta.NoIndex = true
+	if err := pages.Plan(meta).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering plan page: %v", err)
+	}
+}
+
+// HandlePlanEntries renders the current cards of a posted list of saved cards
+func HandlePlanEntries(w http.ResponseWriter, r *http.Request) {
+	items, err := plans.ParseItems(r.PostFormValue("items"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if err := components.PlanEntries(planEntries(r.Context(), items)).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering plan entries: %v", err)
+	}
+}
+
+// HandleSharePlan saves a posted list of cards on the server and sends the
+// visitor to its shareable page
+func HandleSharePlan(w http.ResponseWriter, r *http.Request) {
+	if !sharePlanLimiter.Allow(clientIP(r)) {
+		err := fmt.Errorf("too many shared plans from %s", clientIP(r))
+		WriteError(w, r, apperr.Wrap(apperr.RateLimited, err, "You're doing that too often. Please try again later."))
+		return
+	}
+	items, err := plans.ParseItems(r.PostFormValue("items"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	plan, err := plans.Create(items)
+	if err != nil {
+		WriteError(w, r, fmt.Errorf("saving plan: %w", err))
+		return
+	}
+	log.Printf("Shared plan %s with %d cards", plan.ID, len(plan.Items))
+
+	planURL := "/plans/" + plan.ID
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", planURL)
+		return
+	}
+	http.Redirect(w, r, planURL, http.StatusSeeOther)
+}
+
+// HandleSharedPlan renders a plan saved on the server with live data for
+// each of its cards
+func HandleSharedPlan(w http.ResponseWriter, r *http.Request) {
+	plan, ok := plans.Get(r.PathValue("id"))
+	if !ok {
+		WriteError(w, r, apperr.New(apperr.NotFound, "This plan doesn't exist or has expired"))
+		return
+	}
+	meta := pageMeta(r, i18n.T(r.Context(), "Shared plan")+" - mili.fit", "")
+	meta.NoIndex = true
+	if err := pages.SharedPlan(meta, plan, planEntries(r.Context(), plan.Items)).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering shared plan: %v", err)
+	}
+}
+
+// planEntries looks up the saved cards in the current snapshot, without
+// fetching anything from Google Sheets, so a plan costs no upstream requests
+// however many tabs it lists. Row IDs change whenever a row is edited, so a
+// card whose ID is gone is looked up again by its title and marked as
+// changed. Cards past their Valid Until date are marked as expired instead
+// of shown.
+func planEntries(ctx context.Context, items []plans.Item) []components.PlanEntry {
+	snap := snapshot.Current(ctx)
+	entries := make([]components.PlanEntry, 0, len(items))
+	now := time.Now()
+	for _, item := range items {
+		entry := components.PlanEntry{Item: item}
+
+		tab, _ := snap.Tab(item.SheetID, item.TabName)
+		if tab == nil {
+			// A tab that never loaded may be back later; any other is gone
+			failed := snap.Errors[item.SheetID+"/"+item.TabName] != nil
+			entry.Unavailable = failed
+			entry.Removed = !failed
+		} else {
+			row, found := tabRow(tab, item.RowID)
+			if !found {
+				row, found = titledRow(tab, item.Title)
+				entry.Changed = found
+			}
+			switch {
+			case !found:
+				entry.Removed = true
+			case tab.Expired(row, now):
+				entry.Expired = true
+			default:
+				entry.Row = row
+				entry.Render = tab.Renderer(i18n.Language(ctx))
+				entry.Actions = rowActions(item.Category, tab)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// titledRow finds the row of tab with the given title, ignoring case
+func titledRow(tab *snapshot.Tab, title string) (any, bool) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, false
+	}
+	for _, row := range tab.Rows {
+		if strings.EqualFold(strings.TrimSpace(tab.Title(row)), title) {
+			return row, true
+		}
+	}
+	return nil, false
+}
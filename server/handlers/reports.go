@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/components/sheet_row_cards"
+	"disaster/pages"
+	"disaster/ratelimit"
+	"disaster/reports"
+	"disaster/snapshot"
+)
+
+// maxReportComment caps the length of a report's comment
+const maxReportComment = 500
+
+// reportLimiter caps how many problem reports a client can send
+var reportLimiter = ratelimit.New(20, time.Hour)
+
+// HandleReport records a visitor's report that a card is outdated or wrong
+func HandleReport(w http.ResponseWriter, r *http.Request) {
+	category, sheetID, tabName, rowID := r.PathValue("category"), r.PathValue("sheet"), r.PathValue("tab"), r.PathValue("row")
+
+	if !reportLimiter.Allow(clientIP(r)) {
+		err := fmt.Errorf("too many reports from %s", clientIP(r))
+		WriteError(w, r, apperr.Wrap(apperr.RateLimited, err, "You're doing that too often. Please try again later."))
+		return
+	}
+
+	tab, row, err := lookupRow(snapshot.Current(r.Context()), sheetID, tabName, rowID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		WriteError(w, r, apperr.Wrap(apperr.BadInput, err, "The form couldn't be read."))
+		return
+	}
+	reason := reports.Reason(r.PostForm.Get("reason"))
+	if !reason.Valid() {
+		WriteError(w, r, apperr.New(apperr.BadInput, "Pick what's wrong with this card."))
+		return
+	}
+	comment := strings.TrimSpace(r.PostForm.Get("comment"))
+	if utf8.RuneCountInString(comment) > maxReportComment {
+		WriteError(w, r, apperr.New(apperr.BadInput, "Keep this under %d characters.", maxReportComment))
+		return
+	}
+
+	err = reports.Add(reports.Report{
+		RowID:    rowID,
+		SheetID:  sheetID,
+		TabName:  tabName,
+		Category: category,
+		Title:    tab.Title(row),
+		Reason:   reason,
+		Comment:  comment,
+		Reporter: reports.ReporterKey(clientIP(r)),
+	})
+	if err != nil {
+		WriteError(w, r, fmt.Errorf("saving report of row %s: %w", rowID, err))
+		return
+	}
+	log.Printf("Row %s of %s/%s reported as %s", rowID, sheetID, tabName, reason)
+
+	if r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-Boosted") != "true" {
+		if err := sheet_row_cards.ReportSent().Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering report confirmation: %v", err)
+		}
+		return
+	}
+	http.Redirect(w, r, components.RowURL(category, sheetID, tabName, rowID)+"?reported=1", http.StatusSeeOther)
+}
+
+// HandleReportedRows lists the reported rows, most reported first
+func HandleReportedRows(w http.ResponseWriter, r *http.Request) {
+	meta := components.PageMeta{Title: "Reported cards - mili.fit", NoIndex: true}
+	if err := pages.Reports(meta, reports.Ranked(), reports.Threshold()).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering reports page: %v", err)
+	}
+}
+
+// HandleResolveReports closes the reports of a row once it has been checked
+func HandleResolveReports(w http.ResponseWriter, r *http.Request) {
+	rowID := r.PathValue("row")
+	if err := reports.Resolve(rowID); err != nil {
+		WriteError(w, r, fmt.Errorf("resolving reports of row %s: %w", rowID, err))
+		return
+	}
+	log.Printf("Resolved reports of row %s", rowID)
+	http.Redirect(w, r, "/admin/reports", http.StatusSeeOther)
+}
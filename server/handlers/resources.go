@@ -1,31 +1,43 @@
 package handlers
 
 import (
-	"disaster/components"
-	"disaster/gdrive"
+	"disaster/apperr"
+	"disaster/model"
+	"disaster/pages"
+	"disaster/snapshot"
+	"fmt"
 	"log"
 	"net/http"
 )
 
-func HandleResourcesByCategory(w http.ResponseWriter, r *http.Request) {
-	// Add debug logging
-	log.Printf("Handling resources request for URL: %s", r.URL.String())
+// HandleCategoryPage renders the resources of a category
+func HandleCategoryPage(w http.ResponseWriter, r *http.Request) {
+	category := r.PathValue("category")
 
-	category := r.URL.Query().Get("category")
-	if category == "" {
-		log.Printf("No category provided")
-		http.Error(w, "Category is required", http.StatusBadRequest)
+	snap := snapshot.Current(r.Context())
+	if err, failed := snap.Errors["resources"]; failed && len(snap.Resources) == 0 {
+		WriteError(w, r, err)
 		return
 	}
 
-	log.Printf("Fetching resources for category: %s", category)
-	resources, err := gdrive.GetResourcesByCategory(r.Context(), category)
-	if err != nil {
-		log.Printf("Error fetching resources: %v", err)
-		http.Error(w, "Failed to fetch resources", http.StatusInternalServerError)
+	var resources []model.Resource
+	for _, resource := range snap.Resources {
+		if resource.Category == category {
+			resources = append(resources, resource)
+		}
+	}
+	if len(resources) == 0 {
+		WriteError(w, r, apperr.New(apperr.NotFound, "There are no resources in %q", category))
 		return
 	}
 
-	log.Printf("Found %d resources", len(resources))
-	components.ResourcesList(resources).Render(r.Context(), w)
+	log.Printf("Found %d resources in category %s", len(resources), category)
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+	}
+	meta := pageMeta(r, category+" - mili.fit", fmt.Sprintf("%s resources on mili.fit: %s.", category, listDescription(names, 3)))
+	if err := pages.Category(meta, category, resources).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering category page: %v", err)
+	}
 }
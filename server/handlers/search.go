@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"disaster/components"
+	"disaster/components/sheet_row_cards"
+	"disaster/i18n"
+	"disaster/model"
+	"disaster/pages"
+	"disaster/search"
+	"disaster/snapshot"
+)
+
+// resourcesGroup is the search group of resources from the master sheet
+const resourcesGroup = "resources"
+
+// searchGroupLimit is the number of results shown per group
+const searchGroupLimit = 5
+
+// searchBoosts weights matches by the column they were found in. Columns
+// not listed have a boost of 1.
+var searchBoosts = map[string]float64{
+	"Company":  4,
+	"Name":     4,
+	"Category": 2,
+	"Type":     2,
+	"Products": 2,
+	"Notes":    0.5,
+}
+
+var (
+	searchMu   sync.Mutex
+	searchSnap *snapshot.Snapshot
+	searchIdx  *search.Index
+)
+
+// currentSearchIndex returns the current snapshot and its search index,
+// rebuilding the index when the snapshot has been refreshed
+func currentSearchIndex(ctx context.Context) (*snapshot.Snapshot, *search.Index) {
+	snap := snapshot.Current(ctx)
+
+	searchMu.Lock()
+	defer searchMu.Unlock()
+	if snap != searchSnap {
+		searchIdx = buildSearchIndex(snap)
+		searchSnap = snap
+	}
+	return searchSnap, searchIdx
+}
+
+// buildSearchIndex indexes every resource and tab row of a snapshot
+func buildSearchIndex(snap *snapshot.Snapshot) *search.Index {
+	var docs []search.Document
+
+	for _, resource := range snap.Resources {
+		docs = append(docs, search.Document{
+			ID:    resource.Link,
+			Group: resourcesGroup,
+			Fields: []search.Field{
+				{Text: resource.Name, Boost: searchBoosts["Name"]},
+				{Text: resource.Category, Boost: searchBoosts["Category"]},
+				{Text: resource.Description, Boost: 1},
+			},
+			Value: resource,
+		})
+	}
+
+	for _, tab := range snap.Tabs {
+		fields := sheet_row_cards.RowFields(tab.CardType.RowType)
+		for _, row := range tab.Rows {
+			doc := search.Document{
+				ID:    tab.RowID(row),
+				Group: tab.SheetID + "/" + tab.TabName,
+				Value: row,
+			}
+			for _, field := range fields {
+				boost, ok := searchBoosts[field.Col]
+				if !ok {
+					boost = 1
+				}
+				doc.Fields = append(doc.Fields, search.Field{
+					Text:  sheet_row_cards.FieldText(row, field),
+					Boost: boost,
+				})
+			}
+			docs = append(docs, doc)
+		}
+	}
+
+	return search.NewIndex(docs)
+}
+
+// HandleSearch searches every resource and tab row and renders the results
+// grouped by source. htmx requests get the results fragment, other requests
+// a full page.
+func HandleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	var groups []components.SearchResultGroup
+	if query != "" {
+		snap, idx := currentSearchIndex(r.Context())
+		groups = groupSearchResults(r.Context(), snap, query, idx.Search(query))
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		components.SearchResults(query, groups).Render(r.Context(), w)
+		return
+	}
+	meta := pageMeta(r, "Search - mili.fit", "")
+	meta.NoIndex = true
+	pages.Search(meta, query, groups).Render(r.Context(), w)
+}
+
+// groupSearchResults groups results by source, ordering groups by their best
+// match. Groups are titled and rendered in the language of ctx. Rows past
+// their Valid Until date are left out; the index is only rebuilt with the
+// snapshot, so they are dropped here rather than when indexing.
+func groupSearchResults(ctx context.Context, snap *snapshot.Snapshot, query string, results []search.Result) []components.SearchResultGroup {
+	var groups []components.SearchResultGroup
+	groupIndex := make(map[string]int)
+	now := time.Now()
+
+	for _, result := range results {
+		if result.Group != resourcesGroup {
+			sheetID, tabName, _ := strings.Cut(result.Group, "/")
+			if tab, ok := snap.Tab(sheetID, tabName); ok && tab.Expired(result.Value, now) {
+				continue
+			}
+		}
+		i, ok := groupIndex[result.Group]
+		if !ok {
+			group, ok := newSearchResultGroup(ctx, snap, query, result.Group)
+			if !ok {
+				continue
+			}
+			i = len(groups)
+			groupIndex[result.Group] = i
+			groups = append(groups, group)
+		}
+
+		group := &groups[i]
+		if len(group.Resources)+len(group.Rows) >= searchGroupLimit {
+			group.More++
+			continue
+		}
+		switch value := result.Value.(type) {
+		case model.Resource:
+			group.Resources = append(group.Resources, value)
+		default:
+			group.Rows = append(group.Rows, value)
+		}
+	}
+
+	return groups
+}
+
+// newSearchResultGroup returns an empty result group for a search group key
+func newSearchResultGroup(ctx context.Context, snap *snapshot.Snapshot, query, key string) (components.SearchResultGroup, bool) {
+	if key == resourcesGroup {
+		return components.SearchResultGroup{Title: i18n.T(ctx, "Resources")}, true
+	}
+
+	sheetID, tabName, _ := strings.Cut(key, "/")
+	tab, ok := snap.Tab(sheetID, tabName)
+	if !ok {
+		return components.SearchResultGroup{}, false
+	}
+	return components.SearchResultGroup{
+		Title:   tab.TabName,
+		MoreURL: tabViewURL(sheetCategory(snap, tab.SheetID), tab.SheetID, tab.TabName, tabQuery{Q: query}),
+		Render:  tab.Renderer(i18n.Language(ctx)),
+		Actions: rowActions(sheetCategory(snap, tab.SheetID), tab),
+	}, true
+}
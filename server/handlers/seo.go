@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"disaster/components"
+	"disaster/gdrive"
+	"disaster/sitemap"
+	"disaster/snapshot"
+)
+
+// siteDescription describes pages that have nothing more specific to say
+const siteDescription = "Discounts, free products, pickup locations and services offered to people affected by disasters, collected from community spreadsheets."
+
+// maxDescriptionLength is roughly what search engines show of a description
+const maxDescriptionLength = 160
+
+// pageMeta returns the metadata of the requested page, with its path as the
+// canonical URL so filtered and paged views point at the unfiltered page
+func pageMeta(r *http.Request, title, description string) components.PageMeta {
+	base := baseURL(r)
+	if description == "" {
+		description = siteDescription
+	}
+	return components.PageMeta{
+		Title:       title,
+		Description: truncate(description, maxDescriptionLength),
+		URL:         base + r.URL.EscapedPath(),
+		Image:       base + "/static/img/og_preview.png",
+	}
+}
+
+// listDescription describes a list of names, e.g. "Acme, Globex, Initech and 12 more"
+func listDescription(names []string, shown int) string {
+	var kept []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			kept = append(kept, name)
+		}
+	}
+	if len(kept) <= shown {
+		return strings.Join(kept, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(kept[:shown], ", "), len(kept)-shown)
+}
+
+// truncate shortens s to at most n characters on a word boundary
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	cut := string([]rune(s)[:n-1])
+	if i := strings.LastIndexByte(cut, ' '); i > n/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// HandleSitemap lists the home page and every category, sheet, tab and card
+// page of the current snapshot, except the pages of expired cards
+func HandleSitemap(w http.ResponseWriter, r *http.Request) {
+	base := baseURL(r)
+	snap := snapshot.Current(r.Context())
+
+	urls := []sitemap.URL{{Loc: base + "/", LastMod: snap.TakenAt}}
+	for _, category := range snap.Categories {
+		urls = append(urls, sitemap.URL{Loc: base + components.CategoryURL(category.Name)})
+	}
+
+	seen := make(map[string]bool)
+	for _, resource := range snap.Resources {
+		sheetID := gdrive.ExtractGoogleDocID(resource.Link)
+		if _, configured := gdrive.SheetConfig[sheetID]; !configured || seen[sheetID] {
+			continue
+		}
+		seen[sheetID] = true
+		urls = append(urls, sitemap.URL{Loc: base + components.SheetURL(resource.Category, sheetID)})
+	}
+
+	now := time.Now()
+	for _, tab := range snap.Tabs {
+		category := sheetCategory(snap, tab.SheetID)
+
+		var rowURLs []sitemap.URL
+		var tabModified time.Time
+		for _, row := range tab.Rows {
+			if tab.Expired(row, now) {
+				continue
+			}
+			addedAt := tab.AddedAt(row)
+			if addedAt.After(tabModified) {
+				tabModified = addedAt
+			}
+			rowURLs = append(rowURLs, sitemap.URL{
+				Loc:     base + components.RowURL(category, tab.SheetID, tab.TabName, tab.RowID(row)),
+				LastMod: addedAt,
+			})
+		}
+
+		urls = append(urls, sitemap.URL{Loc: base + components.TabURL(category, tab.SheetID, tab.TabName), LastMod: tabModified})
+		urls = append(urls, rowURLs...)
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	if err := sitemap.Write(w, urls); err != nil {
+		log.Printf("Error writing sitemap: %v", err)
+	}
+}
+
+// HandleRobots allows crawling of the pages and points crawlers at the
+// sitemap. Search results, the API, plans and the admin pages are left out.
+func HandleRobots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "User-agent: *\nAllow: /\nDisallow: /api/\nDisallow: /search\nDisallow: /admin\nDisallow: /plan\nDisallow: /subscriptions/\nDisallow: /zones?\n\nSitemap: %s/sitemap.xml\n", baseURL(r))
+}
@@ -2,209 +2,331 @@ package handlers
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"fmt"
 	"log"
 	"net/http"
-	"net/url"
-	"strings"
+	"time"
 
+	"github.com/a-h/templ"
+
+	"disaster/apperr"
 	"disaster/components"
 	"disaster/components/sheet_row_cards"
-	"disaster/gdrive"
+	"disaster/duplicates"
+	"disaster/geo"
+	"disaster/hours"
+	"disaster/i18n"
+	"disaster/pages"
+	"disaster/plans"
+	"disaster/reports"
+	"disaster/snapshot"
+	"disaster/votes"
 )
 
-// TabsRequest represents the request body for rendering sheet tabs
-type TabsRequest struct {
-	SheetID string `json:"sheetId"`
-}
+// HandleTabPage renders the rows of a tab in the current snapshot, filtered,
+// sorted and paged by the query string. htmx requests from the filter form
+// and infinite scroll get only the part of the page they replace; everything
+// else, including hx-boost navigation, gets the full page.
+func HandleTabPage(w http.ResponseWriter, r *http.Request) {
+	category, sheetID, tabName := r.PathValue("category"), r.PathValue("sheet"), r.PathValue("tab")
 
-// HandleRenderSheetTabs handles the request to render sheet tabs
-func HandleRenderSheetTabs(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet && r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	tab, err := snapshotTab(r.Context(), sheetID, tabName)
+	if err != nil {
+		WriteError(w, r, err)
 		return
 	}
 
-	var sheetID string
-	if r.Method == http.MethodGet {
-		// Extract sheet ID from URL path
-		pathParts := strings.Split(r.URL.Path, "/")
-		if len(pathParts) < 4 {
-			http.Error(w, "Invalid request path", http.StatusBadRequest)
-			return
-		}
-		sheetID = pathParts[3]
-		if sheetID == "" {
-			http.Error(w, "Sheet ID is required", http.StatusBadRequest)
-			return
-		}
-		// URL decode the sheet ID
-		var err error
-		sheetID, err = url.QueryUnescape(sheetID)
-		if err != nil {
-			http.Error(w, "Invalid sheet ID", http.StatusBadRequest)
-			return
-		}
-	} else {
-		// Parse request body for POST
-		var req TabsRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-		sheetID = req.SheetID
+	// Apply the filters, sort order and page from the query string
+	query, err := parseTabQuery(r.Context(), r.URL.Query(), tab)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	matched := query.Filter(tab)
+	pageQuery := query
+	if pageQuery.Limit == 0 {
+		pageQuery.Limit = TabPageSize
 	}
+	page, next := pageQuery.Page(matched)
 
-	// Get the sheet tabs
-	tabs, err := gdrive.GetSpreadsheetInfo(r.Context(), sheetID)
-	if err != nil {
-		log.Printf("Error getting sheet tabs: %v", err)
-		http.Error(w, "Failed to get sheet tabs", http.StatusInternalServerError)
+	dataURL := r.URL.EscapedPath()
+	var nextURL string
+	if next >= 0 {
+		nextQuery := query
+		nextQuery.Cursor = next
+		nextURL = dataURL + "?" + nextQuery.Values().Encode()
+	}
+
+	// Get card renderer for this type
+	if tab.CardType.RenderFunc == nil {
+		WriteError(w, r, fmt.Errorf("no renderer found for card type %s", tab.Component))
 		return
 	}
+	renderer := tab.Renderer(i18n.Language(r.Context()))
+
+	actions := rowActions(category, tab)
+	if query.Near != "" && tab.Locations != nil {
+		actions.Distance = distanceLabel(r.Context(), tab, query.Origin)
+	}
+
+	// Rows past their Valid Until date are archived in the expired view
+	var currentURL, expiredURL string
+	expiredCount := 0
+	if tab.Expires != nil {
+		now := time.Now()
+		for _, row := range tab.Rows {
+			if tab.Expired(row, now) {
+				expiredCount++
+			}
+		}
+		current, expired := query, query
+		current.Expired, expired.Expired = false, true
+		currentURL = tabViewURL(category, sheetID, tabName, current)
+		expiredURL = tabViewURL(category, sheetID, tabName, expired)
+	}
+
+	props := sheet_row_cards.RowCardContainerProps{
+		Rows:         page,
+		Render:       renderer,
+		Actions:      actions,
+		BackURL:      components.SheetURL(category, sheetID),
+		BackLabel:    i18n.T(r.Context(), "Back to tabs"),
+		DataURL:      dataURL,
+		Query:        query.Q,
+		Near:         query.Near,
+		AskNear:      tab.Locations != nil || tab.ServiceAreas != nil,
+		OpenNow:      query.OpenNow,
+		AskOpen:      tab.Hours != nil,
+		Expired:      query.Expired,
+		CurrentURL:   currentURL,
+		ExpiredURL:   expiredURL,
+		ExpiredCount: expiredCount,
+		Sort:         query.Values().Get("sort"),
+		SortOptions:  sheet_row_cards.SortOptions(r.Context(), tab.CardType.RowType),
+		Filters:      query.Facets,
+		Facets:       facetChips(tab, query, dataURL),
+		NextURL:      nextURL,
+		Total:        len(matched),
+		CSVURL:       tabExportURL(sheetID, tabName, query, "csv"),
+		XLSXURL:      tabExportURL(sheetID, tabName, query, "xlsx"),
+		FeedURL:      tabFeedURL(sheetID, tabName),
+		SuggestURL:   components.TabURL(category, sheetID, tabName) + "/suggest",
+	}
+
+	// Infinite scroll requests for later pages only need the cards, not the
+	// filter form
+	var component templ.Component
+	isHTMX := r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-Boosted") != "true"
+	switch {
+	case isHTMX && query.Cursor > 0:
+		component = sheet_row_cards.RowCardPage(page, renderer, props.Actions, nextURL)
+	case isHTMX:
+		component = sheet_row_cards.RowCardContainer(props)
+	default:
+		title := sheetTitle(snapshot.Current(r.Context()), sheetID)
+		companies := make([]string, 0, len(tab.Rows))
+		for _, row := range tab.Rows {
+			companies = append(companies, tab.Title(row))
+		}
+		meta := pageMeta(r, tabName+" - "+title+" - mili.fit",
+			fmt.Sprintf("%d %s listings from %s: %s.", len(tab.Rows), tabName, title, listDescription(companies, 3)))
+		crumbs := []components.Crumb{
+			{Label: category, URL: components.CategoryURL(category)},
+			{Label: title, URL: components.SheetURL(category, sheetID)},
+			{Label: tabName},
+		}
+		subscribe := components.SubscribeTarget{Category: category, SheetID: sheetID, TabName: tabName}
+		component = pages.Tab(meta, tabName, crumbs, props, subscribe)
+	}
 
-	// Render the tabs component
 	var buf bytes.Buffer
-	err = components.SheetTabs(components.SheetTabsProps{
-		SheetID: sheetID,
-		Tabs:    tabs,
-	}).Render(r.Context(), &buf)
-	if err != nil {
-		log.Printf("Error rendering tabs: %v", err)
-		http.Error(w, "Failed to render tabs", http.StatusInternalServerError)
+	if err := component.Render(r.Context(), &buf); err != nil {
+		WriteError(w, r, fmt.Errorf("rendering tab %s: %w", tabName, err))
 		return
 	}
 
-	// Return the rendered HTML directly
 	w.Header().Set("Content-Type", "text/html")
 	w.Write(buf.Bytes())
 }
 
-// HandleSheetData handles the request to get sheet data
-func HandleSheetData(w http.ResponseWriter, r *http.Request) {
-	// Get sheet ID and tab name from URL
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid request path", http.StatusBadRequest)
-		return
-	}
+// facetChips builds the filter chips of a tab view from the query's facet counts
+func facetChips(tab *snapshot.Tab, query tabQuery, dataURL string) []sheet_row_cards.Facet {
+	counts := query.FacetCounts(tab)
 
-	sheetID := pathParts[3]
-	tabName := pathParts[4]
+	var facets []sheet_row_cards.Facet
+	for _, field := range tab.Facets {
+		facet := sheet_row_cards.Facet{Label: field.Col}
+		for _, count := range counts[field.Key] {
+			facet.Values = append(facet.Values, sheet_row_cards.FacetValue{
+				Value:    count.Value,
+				Count:    count.Count,
+				Selected: query.IsSelected(field.Key, count.Value),
+				URL:      dataURL + "?" + query.ToggleFacet(field.Key, count.Value).Values().Encode(),
+			})
+		}
+		facets = append(facets, facet)
+	}
+	return facets
+}
 
-	// URL decode the sheet ID and tab name
-	var err error
-	sheetID, err = url.QueryUnescape(sheetID)
-	if err != nil {
-		http.Error(w, "Invalid sheet ID", http.StatusBadRequest)
-		return
+// rowActions returns the per-card links and actions of a tab's cards
+func rowActions(category string, tab *snapshot.Tab) sheet_row_cards.RowActions {
+	rowURL := func(row any) string {
+		return components.RowURL(category, tab.SheetID, tab.TabName, tab.RowID(row))
 	}
-	tabName, err = url.QueryUnescape(tabName)
-	if err != nil {
-		http.Error(w, "Invalid tab name", http.StatusBadRequest)
-		return
+	actions := sheet_row_cards.RowActions{
+		Permalink: rowURL,
+		ReportURL: func(row any) string {
+			return rowURL(row) + "/report"
+		},
+		Flagged: func(row any) bool {
+			return reports.Flagged(tab.RowID(row))
+		},
+		Save: func(row any) plans.Item {
+			return plans.Item{
+				Category: category,
+				SheetID:  tab.SheetID,
+				TabName:  tab.TabName,
+				RowID:    tab.RowID(row),
+				Title:    tab.Title(row),
+			}
+		},
 	}
-
-	// Get the component config for this tab
-	tabConfig, exists := gdrive.SheetConfig[sheetID]
-	if !exists {
-		log.Printf("No config found for sheet: %s", sheetID)
-		http.Error(w, "Sheet not configured", http.StatusInternalServerError)
-		return
+	if tab.Expires != nil {
+		actions.Expires = tab.ExpiresAt
 	}
-
-	componentConfig, ok := tabConfig[tabName].(map[string]interface{})
-	if !ok {
-		log.Printf("Invalid tab configuration for sheet %s, tab %s", sheetID, tabName)
-		http.Error(w, "Invalid tab configuration", http.StatusInternalServerError)
-		return
+	if snapshot.StaleAfter > 0 {
+		actions.Stale = func(row any) (time.Time, bool) {
+			return snapshot.Stale(tab.RowID(row))
+		}
 	}
-
-	// Get the component type and data range
-	componentName, ok := componentConfig["Component"].(string)
-	if !ok {
-		log.Printf("No component specified in configuration for sheet %s, tab %s", sheetID, tabName)
-		http.Error(w, "No component specified in configuration", http.StatusInternalServerError)
-		return
+	if tab.Hours != nil {
+		now := time.Now()
+		actions.Hours = func(row any) (hours.Status, bool) {
+			schedule, ok := tab.Hours[tab.RowID(row)]
+			return schedule.Status(now), ok
+		}
+	}
+	if duplicates.Merge {
+		actions.Offers = func(row any) (string, int, bool) {
+			return companyOffers(tab.RowID(row))
+		}
+	}
+	if sheet_row_cards.IsConfirmable(tab.CardType.RowType) {
+		actions.ConfirmURL = func(row any) string {
+			return rowURL(row) + "/confirm"
+		}
+		actions.Confirmations = func(row any) votes.Tally {
+			return votes.Summary(tab.RowID(row))
+		}
 	}
+	return actions
+}
 
-	// Get the data from the sheet
-	dataRange, ok := componentConfig["StructuredDataRange"].(string)
-	if !ok {
-		log.Printf("No data range specified in configuration for sheet %s, tab %s", sheetID, tabName)
-		http.Error(w, "No data range specified in configuration", http.StatusInternalServerError)
-		return
+// distanceLabel describes how far the rows of a tab are from origin
+func distanceLabel(ctx context.Context, tab *snapshot.Tab, origin geo.Point) func(row any) string {
+	return func(row any) string {
+		point, ok := tab.Locations[tab.RowID(row)]
+		if !ok {
+			return ""
+		}
+		return i18n.T(ctx, "%.1f mi away", geo.Miles(origin, point))
 	}
+}
 
-	// Get the card type
-	cardType, ok := sheet_row_cards.GetCardType(componentName)
-	if !ok {
-		log.Printf("Unknown component type: %s", componentName)
-		http.Error(w, "Unknown component type", http.StatusInternalServerError)
-		return
+// tabViewURL returns the shareable page URL of a filtered tab view
+func tabViewURL(category, sheetID, tabName string, query tabQuery) string {
+	query.Cursor = 0
+	query.Limit = 0
+	u := components.TabURL(category, sheetID, tabName)
+	if values := query.Values(); len(values) > 0 {
+		u += "?" + values.Encode()
 	}
+	return u
+}
+
+// HandleRowPage renders the permalink page of a single row from the current
+// snapshot. Rows added since it was taken are found once it is refreshed.
+func HandleRowPage(w http.ResponseWriter, r *http.Request) {
+	category, sheetID, tabName, rowID := r.PathValue("category"), r.PathValue("sheet"), r.PathValue("tab"), r.PathValue("row")
 
-	// Get the data from the sheet
-	data, err := gdrive.GetSheetData(r.Context(), sheetID, tabName, dataRange)
+	snap := snapshot.Current(r.Context())
+	tab, row, err := lookupRow(snap, sheetID, tabName, rowID)
 	if err != nil {
-		log.Printf("Error getting sheet data: %v", err)
-		http.Error(w, "Failed to get sheet data", http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
 
-	// Get headers and rows
-	if len(data) < 1 {
-		http.Error(w, "No data found", http.StatusNotFound)
+	text, err := sheet_row_cards.CardText(r.Context(), tab.CardType.RenderFunc, row)
+	if err != nil {
+		WriteError(w, r, fmt.Errorf("rendering row %s of tab %s: %w", rowID, tabName, err))
 		return
 	}
 
-	headers := data[0]
-	rows := data[1:]
+	title := sheetTitle(snap, sheetID)
+	company := tab.Title(row)
+	meta := pageMeta(r, company+" - "+tabName+" - mili.fit", text)
+	meta.Type = "article"
+	crumbs := []components.Crumb{
+		{Label: category, URL: components.CategoryURL(category)},
+		{Label: title, URL: components.SheetURL(category, sheetID)},
+		{Label: tabName, URL: components.TabURL(category, sheetID, tabName)},
+		{Label: company},
+	}
 
-	log.Printf("Headers: %v", headers)
+	tabURL := components.TabURL(category, sheetID, tabName)
+	actions := rowActions(category, tab)
+	actions.Permalink = nil
+	reported := r.URL.Query().Get("reported") != ""
+	if reported {
+		actions.ReportURL = nil
+	}
+	if err := pages.Row(meta, crumbs, tabURL, row, tab.Renderer(i18n.Language(r.Context())), actions, reported).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering row page: %v", err)
+	}
+}
 
-	// Map column names to indices
-	colMap := make(map[string]int)
-	for j, header := range headers {
-		headerStr, ok := header.(string)
-		if !ok {
-			log.Printf("Warning: header at index %d is not a string: %v", j, header)
-			continue
-		}
-		colMap[headerStr] = j
-		log.Printf("Found column: %s at index %d", headerStr, j)
-	}
-
-	// Parse rows into structs
-	var rowsData []any
-	for i, row := range rows {
-		log.Printf("Processing row %d: %v", i, row)
-		rowData, err := sheet_row_cards.ParseRowData(cardType, row, colMap)
-		if err != nil {
-			log.Printf("Warning: error parsing row %d: %v", i, err)
-			continue
-		}
-		rowsData = append(rowsData, rowData)
+// snapshotTab returns a tab of the current snapshot, or the error that kept
+// it out of the snapshot
+func snapshotTab(ctx context.Context, sheetID, tabName string) (*snapshot.Tab, error) {
+	snap := snapshot.Current(ctx)
+	if tab, ok := snap.Tab(sheetID, tabName); ok {
+		return tab, nil
 	}
+	if err := snap.Errors[sheetID+"/"+tabName]; err != nil {
+		return nil, err
+	}
+	return nil, snapshot.ErrTabNotConfigured
+}
 
-	// Get card renderer for this type
-	renderer := cardType.RenderFunc
-	if renderer == nil {
-		http.Error(w, "No renderer found for card type", http.StatusInternalServerError)
-		return
+// lookupRow finds a row in the snapshot. A row that isn't in it is reported
+// as no longer listed rather than fetched from Google Sheets, so made-up row
+// IDs cost no upstream requests.
+func lookupRow(snap *snapshot.Snapshot, sheetID, tabName, rowID string) (*snapshot.Tab, any, error) {
+	tab, row, found := findRow(snap, sheetID, tabName, rowID)
+	if !found {
+		return nil, nil, apperr.New(apperr.NotFound, "This card is no longer listed")
 	}
+	return tab, row, nil
+}
 
-	// Render using the card type's render function
-	var buf bytes.Buffer
-	err = sheet_row_cards.RowCardContainer(rowsData, renderer).Render(r.Context(), &buf)
-	if err != nil {
-		http.Error(w, "Failed to render component", http.StatusInternalServerError)
-		return
+// findRow looks up a row of a snapshot tab by its ID
+func findRow(snap *snapshot.Snapshot, sheetID, tabName, rowID string) (*snapshot.Tab, any, bool) {
+	tab, ok := snap.Tab(sheetID, tabName)
+	if !ok {
+		return nil, nil, false
 	}
+	row, ok := tabRow(tab, rowID)
+	return tab, row, ok
+}
 
-	// Return the rendered HTML
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"html": buf.String(),
-	})
+// tabRow looks up a row of a tab by its ID
+func tabRow(tab *snapshot.Tab, rowID string) (any, bool) {
+	for _, row := range tab.Rows {
+		if tab.RowID(row) == rowID {
+			return row, true
+		}
+	}
+	return nil, false
 }
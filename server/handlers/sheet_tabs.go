@@ -5,7 +5,12 @@ import (
 	"log"
 	"net/http"
 
+	"disaster/apperr"
+	"disaster/components"
 	"disaster/gdrive"
+	"disaster/model"
+	"disaster/pages"
+	"disaster/snapshot"
 )
 
 // HandleSheetTabs handles requests for Google Sheet tab information
@@ -19,16 +24,14 @@ func HandleSheetTabs(w http.ResponseWriter, r *http.Request) {
 	// Expected format: /api/sheet-tabs/{sheetID}
 	sheetID := r.URL.Path[len("/api/sheet-tabs/"):]
 	if sheetID == "" {
-		log.Printf("Sheet ID not provided in request")
-		http.Error(w, "Sheet ID not provided", http.StatusBadRequest)
+		WriteError(w, r, apperr.New(apperr.BadInput, "Sheet ID not provided"))
 		return
 	}
 
 	// Get sheet tabs
 	tabs, err := gdrive.GetSpreadsheetInfo(r.Context(), sheetID)
 	if err != nil {
-		log.Printf("Error getting spreadsheet info for sheet %s: %v", sheetID, err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
 
@@ -36,7 +39,64 @@ func HandleSheetTabs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(tabs); err != nil {
 		log.Printf("Error encoding tabs response: %v", err)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// HandleSheetPage renders the tabs of a configured sheet linked from a category
+func HandleSheetPage(w http.ResponseWriter, r *http.Request) {
+	category, sheetID := r.PathValue("category"), r.PathValue("sheet")
+	if _, exists := gdrive.SheetConfig[sheetID]; !exists {
+		WriteError(w, r, apperr.New(apperr.NotConfigured, "Sheet not configured"))
 		return
 	}
+
+	tabs, err := gdrive.GetSpreadsheetInfo(r.Context(), sheetID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	snap := snapshot.Current(r.Context())
+	title := sheetTitle(snap, sheetID)
+	resource, _ := sheetResource(snap, sheetID)
+	meta := pageMeta(r, title+" - mili.fit", resource.Description)
+	crumbs := []components.Crumb{
+		{Label: category, URL: components.CategoryURL(category)},
+		{Label: title},
+	}
+	err = pages.Sheet(meta, title, crumbs, components.SheetTabsProps{
+		Tabs:     tabs,
+		SheetID:  sheetID,
+		Category: category,
+	}).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering sheet page: %v", err)
+	}
+}
+
+// sheetResource returns the master sheet resource that links to a sheet
+func sheetResource(snap *snapshot.Snapshot, sheetID string) (model.Resource, bool) {
+	for _, resource := range snap.Resources {
+		if gdrive.ExtractGoogleDocID(resource.Link) == sheetID {
+			return resource, true
+		}
+	}
+	return model.Resource{}, false
+}
+
+// sheetTitle returns the name of the resource that links to a sheet
+func sheetTitle(snap *snapshot.Snapshot, sheetID string) string {
+	if resource, ok := sheetResource(snap, sheetID); ok && resource.Name != "" {
+		return resource.Name
+	}
+	return "Sheet"
+}
+
+// sheetCategory returns the category of the resource that links to a sheet,
+// for building page URLs of sheets reached from feeds and search
+func sheetCategory(snap *snapshot.Snapshot, sheetID string) string {
+	if resource, ok := sheetResource(snap, sheetID); ok && resource.Category != "" {
+		return resource.Category
+	}
+	return "Resources"
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/digest"
+	"disaster/i18n"
+	"disaster/mail"
+	"disaster/pages"
+	"disaster/ratelimit"
+	"disaster/snapshot"
+)
+
+// Mailer sends confirmation links and digests; main replaces it with the
+// configured mailer
+var Mailer mail.Mailer = mail.LogMailer{}
+
+// subscribeLimiter caps how many subscriptions a client can start, since
+// each sends an email
+var subscribeLimiter = ratelimit.New(10, time.Hour)
+
+// HandleSubscribe starts a digest subscription to a category or tab and
+// emails the address a link to confirm it. The category of a tab comes from
+// the master sheet, never the form, since it is quoted in the email. Nothing
+// is accepted when BASE_URL is unset, as the email couldn't link back.
+func HandleSubscribe(w http.ResponseWriter, r *http.Request) {
+	if _, ok := digest.BaseURL(); !ok {
+		WriteError(w, r, apperr.New(apperr.NotConfigured, "Email updates aren't available on mili.fit right now."))
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		WriteError(w, r, apperr.Wrap(apperr.BadInput, err, "The form couldn't be read."))
+		return
+	}
+	category, sheetID, tabName := r.PostForm.Get("category"), r.PostForm.Get("sheet"), r.PostForm.Get("tab")
+
+	snap := snapshot.Current(r.Context())
+	if sheetID != "" {
+		if _, err := snapshot.Settings(sheetID, tabName); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+		category = sheetCategory(snap, sheetID)
+	} else if !hasCategory(snap, category) {
+		WriteError(w, r, apperr.New(apperr.NotFound, "There are no resources in %q", category))
+		return
+	}
+
+	if !subscribeLimiter.Allow(clientIP(r)) {
+		err := fmt.Errorf("too many subscriptions from %s", clientIP(r))
+		WriteError(w, r, apperr.Wrap(apperr.RateLimited, err, "You're doing that too often. Please try again later."))
+		return
+	}
+
+	sub, err := digest.Subscribe(digest.Subscription{
+		Email:     r.PostForm.Get("email"),
+		Frequency: digest.Frequency(r.PostForm.Get("frequency")),
+		Category:  category,
+		SheetID:   sheetID,
+		TabName:   tabName,
+		Language:  i18n.Language(r.Context()).String(),
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if err := digest.SendConfirmation(r.Context(), Mailer, sub); err != nil {
+		WriteError(w, r, fmt.Errorf("sending confirmation for %s: %w", sub.Target(), err))
+		return
+	}
+	log.Printf("Sent subscription confirmation for %s", sub.Target())
+
+	renderSubscriptionPage(w, r,
+		i18n.T(r.Context(), "Check your email"),
+		i18n.T(r.Context(), "We sent a link to %s. Open it to confirm your subscription; digests start once you do.", sub.Email),
+		"")
+}
+
+// HandleConfirmSubscription confirms a subscription from the link emailed
+// to its address
+func HandleConfirmSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, err := digest.Confirm(r.URL.Query().Get("token"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	log.Printf("Confirmed %s subscription to %s", sub.Frequency, sub.Target())
+	renderSubscriptionPage(w, r,
+		i18n.T(r.Context(), "Subscription confirmed"),
+		i18n.T(r.Context(), "You'll get a %s digest of new and changed listings in %s.", i18n.T(r.Context(), string(sub.Frequency)), sub.Target()),
+		"")
+}
+
+// HandleUnsubscribePage asks the subscriber to confirm unsubscribing, so
+// link scanners opening the emailed link don't unsubscribe them
+func HandleUnsubscribePage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	sub, ok := digest.Get(token)
+	if !ok {
+		renderSubscriptionPage(w, r,
+			i18n.T(r.Context(), "You're unsubscribed"),
+			i18n.T(r.Context(), "You won't get any more emails from this subscription."),
+			"")
+		return
+	}
+	renderSubscriptionPage(w, r,
+		i18n.T(r.Context(), "Unsubscribe"),
+		i18n.T(r.Context(), "Stop sending %s digests of %s to %s?", i18n.T(r.Context(), string(sub.Frequency)), sub.Target(), sub.Email),
+		"/subscriptions/unsubscribe?token="+url.QueryEscape(token))
+}
+
+// HandleUnsubscribe ends a subscription, either from the unsubscribe page or
+// a one-click List-Unsubscribe post (RFC 8058)
+func HandleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	sub, ok := digest.Get(token)
+	if ok {
+		if err := digest.Unsubscribe(token); err != nil {
+			WriteError(w, r, fmt.Errorf("unsubscribing from %s: %w", sub.Target(), err))
+			return
+		}
+		log.Printf("Unsubscribed from %s", sub.Target())
+	}
+	renderSubscriptionPage(w, r,
+		i18n.T(r.Context(), "You're unsubscribed"),
+		i18n.T(r.Context(), "You won't get any more emails from this subscription."),
+		"")
+}
+
+// renderSubscriptionPage renders a subscription message, with an unsubscribe
+// button when unsubscribeAction is set
+func renderSubscriptionPage(w http.ResponseWriter, r *http.Request, title, message, unsubscribeAction string) {
+	meta := components.PageMeta{Title: title + " - mili.fit", NoIndex: true}
+	page := pages.Subscription(meta, title, message, unsubscribeAction, i18n.T(r.Context(), "Unsubscribe"))
+	if err := page.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering subscription page: %v", err)
+	}
+}
+
+// hasCategory reports whether any resource of the snapshot is in category
+func hasCategory(snap *snapshot.Snapshot, category string) bool {
+	for _, resource := range snap.Resources {
+		if resource.Category == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/components/sheet_row_cards"
+	"disaster/i18n"
+	"disaster/moderation"
+	"disaster/pages"
+	"disaster/ratelimit"
+	"disaster/snapshot"
+)
+
+// suggestLimiter caps how many suggestions a client can send
+var suggestLimiter = ratelimit.New(5, time.Hour)
+
+// HandleSuggestPage renders the form for suggesting a new row of a tab
+func HandleSuggestPage(w http.ResponseWriter, r *http.Request) {
+	renderSuggestPage(w, r, http.StatusOK, nil, nil)
+}
+
+// HandleSuggest queues a suggested row for moderation. Invalid suggestions get
+// the form back with what needs fixing.
+func HandleSuggest(w http.ResponseWriter, r *http.Request) {
+	category, sheetID, tabName := r.PathValue("category"), r.PathValue("sheet"), r.PathValue("tab")
+
+	settings, err := snapshot.Settings(sheetID, tabName)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		WriteError(w, r, apperr.Wrap(apperr.BadInput, err, "The form couldn't be read."))
+		return
+	}
+
+	sentURL := r.URL.EscapedPath() + "?sent=1"
+
+	// Only bots fill in the hidden honeypot field; let them think it worked
+	if r.PostForm.Get("homepage") != "" {
+		log.Printf("Dropping suggestion for %s/%s from %s: honeypot filled", sheetID, tabName, clientIP(r))
+		http.Redirect(w, r, sentURL, http.StatusSeeOther)
+		return
+	}
+
+	suggestion, problems := sheet_row_cards.ParseForm(r.Context(), settings.CardType.RowType, r.PostForm)
+	if len(problems) > 0 {
+		renderSuggestPage(w, r, http.StatusUnprocessableEntity, r.PostForm, problems)
+		return
+	}
+
+	// Only queued suggestions count, so fixing a mistake doesn't use one up
+	if !suggestLimiter.Allow(clientIP(r)) {
+		err := fmt.Errorf("too many suggestions from %s", clientIP(r))
+		WriteError(w, r, apperr.Wrap(apperr.RateLimited, err, "You've sent a lot of suggestions. Please try again in an hour."))
+		return
+	}
+
+	sub, err := moderation.Submit(moderation.Submission{
+		SheetID:  sheetID,
+		TabName:  tabName,
+		Category: category,
+		Values:   suggestion.Values,
+		Links:    suggestion.Links,
+	})
+	if err != nil {
+		WriteError(w, r, fmt.Errorf("saving suggestion for %s/%s: %w", sheetID, tabName, err))
+		return
+	}
+	log.Printf("Queued suggestion %s for %s/%s", sub.ID, sheetID, tabName)
+	http.Redirect(w, r, sentURL, http.StatusSeeOther)
+}
+
+// renderSuggestPage renders the suggestion form of the requested tab with the
+// given values and problems
+func renderSuggestPage(w http.ResponseWriter, r *http.Request, status int, values url.Values, problems map[string]string) {
+	category, sheetID, tabName := r.PathValue("category"), r.PathValue("sheet"), r.PathValue("tab")
+
+	settings, err := snapshot.Settings(sheetID, tabName)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	title := sheetTitle(snapshot.Current(r.Context()), sheetID)
+	tabURL := components.TabURL(category, sheetID, tabName)
+	heading := i18n.T(r.Context(), "Suggest a resource")
+	meta := pageMeta(r, heading+" - "+tabName+" - mili.fit", "")
+	meta.NoIndex = true
+	crumbs := []components.Crumb{
+		{Label: category, URL: components.CategoryURL(category)},
+		{Label: title, URL: components.SheetURL(category, sheetID)},
+		{Label: tabName, URL: tabURL},
+		{Label: heading},
+	}
+	props := sheet_row_cards.SuggestFormProps{
+		Action: r.URL.EscapedPath(),
+		Fields: sheet_row_cards.FormFields(settings.CardType.RowType),
+		Values: values,
+		Errors: problems,
+	}
+
+	var buf bytes.Buffer
+	sent := r.Method == http.MethodGet && r.URL.Query().Get("sent") != ""
+	if err := pages.Suggest(meta, heading, crumbs, tabURL, props, sent).Render(r.Context(), &buf); err != nil {
+		WriteError(w, r, fmt.Errorf("rendering suggestion form: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"disaster/apperr"
+	"disaster/components/sheet_row_cards"
+	"disaster/geo"
+	"disaster/snapshot"
+	"disaster/votes"
+)
+
+// maxPageSize caps the limit query parameter
+const maxPageSize = 100
+
+// TabPageSize is the number of cards rendered per page of a tab view when no
+// limit is given. Zero renders every row, as static exports do.
+var TabPageSize = 20
+
+// tabQuery holds the filters, sort order and page requested for a tab's rows.
+// It round-trips through URL query parameters so a filtered view can be shared:
+//
+//	q=<text>&<facet key>=<value>&near=<ZIP>&open=now&view=expired&sort=[-]<field key>&cursor=<n>&limit=<n>
+type tabQuery struct {
+	Q       string
+	Facets  map[string][]string // facet field key to accepted values
+	Near    string              // ZIP code the visitor is near, empty for none
+	Origin  geo.Point           // where Near is
+	OpenNow bool                // only rows whose opening hours say they are open
+	Expired bool                // the archive of expired rows instead of the current ones
+	Sort    string              // field key, empty for sheet order
+	Desc    bool
+	Cursor  int // index of the first row of the page
+	Limit   int // page size, zero for all rows
+}
+
+// parseTabQuery reads a tabQuery from URL query parameters. Facet parameters
+// are only recognised for the facet columns configured for the tab; malformed
+// sort, open, view, cursor and limit parameters and unknown ZIP codes are apperr.BadInput
+// errors. A ZIP code sorts rows with an address nearest first unless another
+// order is asked for.
+func parseTabQuery(ctx context.Context, values url.Values, tab *snapshot.Tab) (tabQuery, error) {
+	query := tabQuery{
+		Q:      strings.TrimSpace(values.Get("q")),
+		Facets: make(map[string][]string),
+	}
+
+	for _, facet := range tab.Facets {
+		for _, value := range values[facet.Key] {
+			if value != "" {
+				query.Facets[facet.Key] = append(query.Facets[facet.Key], value)
+			}
+		}
+	}
+
+	if near := strings.TrimSpace(values.Get("near")); near != "" {
+		if !geo.IsZIP(near) {
+			return query, apperr.New(apperr.BadInput, "Enter a 5-digit ZIP code.")
+		}
+		origin, err := geo.Default.Geocode(ctx, near)
+		if errors.Is(err, geo.ErrNotFound) {
+			return query, apperr.New(apperr.BadInput, "We don't know where ZIP code %s is yet.", near)
+		}
+		if err != nil {
+			return query, err
+		}
+		query.Near, query.Origin = near, origin
+	}
+
+	switch open := values.Get("open"); open {
+	case "":
+	case sheet_row_cards.OpenNowFilter:
+		query.OpenNow = true
+	default:
+		return query, apperr.New(apperr.BadInput, "Invalid open filter %q", open)
+	}
+
+	switch view := values.Get("view"); view {
+	case "":
+	case sheet_row_cards.ExpiredView:
+		query.Expired = true
+	default:
+		return query, apperr.New(apperr.BadInput, "Invalid view %q", view)
+	}
+
+	locatable := sheet_row_cards.IsLocatable(tab.CardType.RowType)
+	if sortKey := values.Get("sort"); sortKey != "" {
+		query.Desc = strings.HasPrefix(sortKey, "-")
+		query.Sort = strings.TrimPrefix(sortKey, "-")
+		byConfirmed := query.Sort == sheet_row_cards.ConfirmedSortKey && sheet_row_cards.IsConfirmable(tab.CardType.RowType)
+		byDistance := query.Sort == sheet_row_cards.DistanceSortKey && locatable
+		if _, ok := sheet_row_cards.FieldByKey(tab.CardType.RowType, query.Sort); !ok && !byConfirmed && !byDistance {
+			return query, apperr.New(apperr.BadInput, "Unknown sort field %q", query.Sort)
+		}
+		if byDistance && query.Near == "" {
+			return query, apperr.New(apperr.BadInput, "Enter a ZIP code to sort by distance.")
+		}
+	} else if query.Near != "" && locatable {
+		query.Sort = sheet_row_cards.DistanceSortKey
+	}
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 {
+			return query, apperr.New(apperr.BadInput, "Invalid cursor %q", cursor)
+		}
+		query.Cursor = n
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return query, apperr.New(apperr.BadInput, "Invalid limit %q", limit)
+		}
+		query.Limit = min(n, maxPageSize)
+	}
+
+	return query, nil
+}
+
+// Values encodes the query as URL query parameters
+func (q tabQuery) Values() url.Values {
+	values := url.Values{}
+	if q.Q != "" {
+		values.Set("q", q.Q)
+	}
+	for key, accepted := range q.Facets {
+		for _, value := range accepted {
+			values.Add(key, value)
+		}
+	}
+	if q.Near != "" {
+		values.Set("near", q.Near)
+	}
+	if q.OpenNow {
+		values.Set("open", sheet_row_cards.OpenNowFilter)
+	}
+	if q.Expired {
+		values.Set("view", sheet_row_cards.ExpiredView)
+	}
+	if q.Sort != "" {
+		if q.Desc {
+			values.Set("sort", "-"+q.Sort)
+		} else {
+			values.Set("sort", q.Sort)
+		}
+	}
+	if q.Cursor > 0 {
+		values.Set("cursor", strconv.Itoa(q.Cursor))
+	}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	return values
+}
+
+// Filter returns the rows of the tab matching the text and facet filters, in
+// the requested sort order. Rows past their Valid Until date are only in the
+// expired view. With a ZIP code, rows whose service area doesn't
+// cover it are left out; rows with no service area, or one that couldn't be
+// read, are kept. Filtering to rows open now leaves out rows without readable
+// opening hours.
+func (q tabQuery) Filter(tab *snapshot.Tab) []any {
+	now := time.Now()
+	var matched []any
+	for _, row := range tab.Rows {
+		if tab.Expired(row, now) != q.Expired || !sheet_row_cards.MatchesText(row, q.Q) {
+			continue
+		}
+		if !q.matchesFacets(row, tab.Facets, "") {
+			continue
+		}
+		if q.Near != "" && tab.ServiceAreas != nil {
+			if area, ok := tab.ServiceAreas[tab.RowID(row)]; ok && area.Known() && !area.Covers(q.Near, q.Origin) {
+				continue
+			}
+		}
+		if q.OpenNow {
+			if schedule, ok := tab.Hours[tab.RowID(row)]; !ok || !schedule.IsOpen(now) {
+				continue
+			}
+		}
+		matched = append(matched, row)
+	}
+
+	if q.Sort == sheet_row_cards.ConfirmedSortKey {
+		sortByConfirmed(matched, tab, q.Desc)
+	} else if q.Sort == sheet_row_cards.DistanceSortKey {
+		sortByDistance(matched, tab, q.Origin)
+	} else if field, ok := sheet_row_cards.FieldByKey(tab.CardType.RowType, q.Sort); ok {
+		sheet_row_cards.SortRows(matched, field, q.Desc)
+	}
+
+	return matched
+}
+
+// sortByConfirmed orders rows by when they were last confirmed working, keeping
+// sheet order among rows confirmed at the same time or never
+func sortByConfirmed(rows []any, tab *snapshot.Tab, desc bool) {
+	confirmed := make([]time.Time, len(rows))
+	order := make([]int, len(rows))
+	for i, row := range rows {
+		confirmed[i] = votes.Summary(tab.RowID(row)).LastWorked
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := confirmed[order[i]], confirmed[order[j]]
+		if desc {
+			return a.After(b)
+		}
+		return a.Before(b)
+	})
+	sorted := make([]any, len(rows))
+	for i, index := range order {
+		sorted[i] = rows[index]
+	}
+	copy(rows, sorted)
+}
+
+// sortByDistance orders rows nearest to origin first. Rows that couldn't be
+// placed on the map go last, in sheet order.
+func sortByDistance(rows []any, tab *snapshot.Tab, origin geo.Point) {
+	miles := make([]float64, len(rows))
+	for i, row := range rows {
+		miles[i] = math.Inf(1)
+		if point, ok := tab.Locations[tab.RowID(row)]; ok {
+			miles[i] = geo.Miles(origin, point)
+		}
+	}
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return miles[order[i]] < miles[order[j]] })
+	sorted := make([]any, len(rows))
+	for i, index := range order {
+		sorted[i] = rows[index]
+	}
+	copy(rows, sorted)
+}
+
+// matchesFacets reports whether a row matches every facet filter except the
+// one keyed skip. Values of the same facet are alternatives; different facets
+// must all match.
+func (q tabQuery) matchesFacets(row any, facets []sheet_row_cards.Field, skip string) bool {
+	for _, facet := range facets {
+		if facet.Key == skip {
+			continue
+		}
+		accepted := q.Facets[facet.Key]
+		if len(accepted) == 0 {
+			continue
+		}
+		if !q.IsSelected(facet.Key, sheet_row_cards.FieldText(row, facet)) {
+			return false
+		}
+	}
+	return true
+}
+
+// FacetCount is the number of rows having a value of a facet column
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// FacetCounts counts the values of every facet column of the tab, keyed by
+// facet key. Each facet is counted over the rows of the view matching the
+// text filter and the filters of the other facets, so a count is the number
+// of rows the view would show if that value were added to the filter. Values
+// differing only in case are counted together, since filters match them
+// alike. Values are ordered by count, most common first; selected values are
+// always included.
+func (q tabQuery) FacetCounts(tab *snapshot.Tab) map[string][]FacetCount {
+	now := time.Now()
+	var textMatched []any
+	for _, row := range tab.Rows {
+		if tab.Expired(row, now) == q.Expired && sheet_row_cards.MatchesText(row, q.Q) {
+			textMatched = append(textMatched, row)
+		}
+	}
+
+	result := make(map[string][]FacetCount)
+	for _, facet := range tab.Facets {
+		// Values are counted ignoring case, as they are matched, under the
+		// spelling of the first row having them
+		counts := make(map[string]int)
+		spellings := make(map[string]string)
+		for _, row := range textMatched {
+			if !q.matchesFacets(row, tab.Facets, facet.Key) {
+				continue
+			}
+			if value := sheet_row_cards.FieldText(row, facet); value != "" {
+				folded := strings.ToLower(value)
+				if _, ok := spellings[folded]; !ok {
+					spellings[folded] = value
+				}
+				counts[folded]++
+			}
+		}
+		// Keep selected values that no longer match any row so they can be cleared
+		for _, selected := range q.Facets[facet.Key] {
+			folded := strings.ToLower(selected)
+			if _, ok := spellings[folded]; !ok {
+				spellings[folded] = selected
+				counts[folded] = 0
+			}
+		}
+
+		values := make([]FacetCount, 0, len(counts))
+		for folded, count := range counts {
+			values = append(values, FacetCount{Value: spellings[folded], Count: count})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Value < values[j].Value
+		})
+		result[facet.Key] = values
+	}
+
+	return result
+}
+
+// IsSelected reports whether a facet value is part of the filter
+func (q tabQuery) IsSelected(key, value string) bool {
+	return slices.ContainsFunc(q.Facets[key], func(a string) bool { return strings.EqualFold(a, value) })
+}
+
+// ToggleFacet returns a copy of the query with a facet value added to or
+// removed from the filter, starting again from the first page
+func (q tabQuery) ToggleFacet(key, value string) tabQuery {
+	toggled := q
+	toggled.Cursor = 0
+	toggled.Facets = make(map[string][]string, len(q.Facets))
+	for k, values := range q.Facets {
+		toggled.Facets[k] = slices.Clone(values)
+	}
+
+	if q.IsSelected(key, value) {
+		toggled.Facets[key] = slices.DeleteFunc(toggled.Facets[key], func(a string) bool { return strings.EqualFold(a, value) })
+	} else {
+		toggled.Facets[key] = append(toggled.Facets[key], value)
+	}
+	return toggled
+}
+
+// Page returns the page of rows selected by the cursor and limit, and the
+// cursor of the following page, or -1 when there are no more rows
+func (q tabQuery) Page(rows []any) ([]any, int) {
+	if q.Cursor >= len(rows) {
+		return nil, -1
+	}
+	if q.Limit == 0 || q.Cursor+q.Limit >= len(rows) {
+		return rows[q.Cursor:], -1
+	}
+	return rows[q.Cursor : q.Cursor+q.Limit], q.Cursor + q.Limit
+}
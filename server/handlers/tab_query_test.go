@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"disaster/components/sheet_row_cards"
+	"disaster/snapshot"
+)
+
+type facetRow struct {
+	Company string `col:"Company" json:"company"`
+	Type    string `col:"Type" json:"type"`
+	City    string `col:"City" json:"city"`
+}
+
+// facetTab returns a tab of facetRows faceted on Type and City
+func facetTab(rows ...facetRow) *snapshot.Tab {
+	rowType := reflect.TypeOf(facetRow{})
+	tab := &snapshot.Tab{SheetID: "sheet", TabName: "Offers", CardType: sheet_row_cards.CardType{RowType: rowType}}
+	for _, key := range []string{"type", "city"} {
+		field, _ := sheet_row_cards.FieldByKey(rowType, key)
+		tab.Facets = append(tab.Facets, field)
+	}
+	for _, row := range rows {
+		tab.Rows = append(tab.Rows, row)
+	}
+	return tab
+}
+
+func TestFacetCounts(t *testing.T) {
+	tab := facetTab(
+		facetRow{"Acme Shelter", "Shelter", "Austin"},
+		facetRow{"Bay Shelter", "shelter", "austin"},
+		facetRow{"City Food", "Food", "Austin"},
+		facetRow{"Dell Food", "FOOD", "Dallas"},
+		facetRow{"Elm Clinic", "Clinic", "Dallas"},
+		facetRow{"Fir Shelter", "SHELTER", ""},
+	)
+	tests := []struct {
+		name   string
+		query  tabQuery
+		facet  string
+		counts []FacetCount
+	}{
+		{
+			name:   "values differing in case are counted together under the first spelling",
+			facet:  "type",
+			counts: []FacetCount{{"Shelter", 3}, {"Food", 2}, {"Clinic", 1}},
+		},
+		{
+			name:   "blank values aren't counted",
+			facet:  "city",
+			counts: []FacetCount{{"Austin", 3}, {"Dallas", 2}},
+		},
+		{
+			name:   "other facets filter the counts, ignoring case",
+			query:  tabQuery{Facets: map[string][]string{"city": {"AUSTIN"}}},
+			facet:  "type",
+			counts: []FacetCount{{"Shelter", 2}, {"Food", 1}},
+		},
+		{
+			name:   "a facet's own filter doesn't narrow its counts",
+			query:  tabQuery{Facets: map[string][]string{"type": {"food"}}},
+			facet:  "type",
+			counts: []FacetCount{{"Shelter", 3}, {"Food", 2}, {"Clinic", 1}},
+		},
+		{
+			name:   "selected values matching no row are kept",
+			query:  tabQuery{Facets: map[string][]string{"city": {"Dallas"}, "type": {"Pharmacy"}}},
+			facet:  "type",
+			counts: []FacetCount{{"Clinic", 1}, {"FOOD", 1}, {"Pharmacy", 0}},
+		},
+		{
+			name:   "the text filter narrows the counts",
+			query:  tabQuery{Q: "shelter"},
+			facet:  "city",
+			counts: []FacetCount{{"Austin", 2}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.query.FacetCounts(tab)[test.facet]
+			if !slices.Equal(got, test.counts) {
+				t.Errorf("FacetCounts()[%q] = %v, want %v", test.facet, got, test.counts)
+			}
+		})
+	}
+}
+
+func TestFilterFacetsIgnoreCase(t *testing.T) {
+	tab := facetTab(
+		facetRow{"Acme Shelter", "Shelter", "Austin"},
+		facetRow{"Bay Shelter", "shelter", "Dallas"},
+		facetRow{"City Food", "Food", "Austin"},
+	)
+	query := tabQuery{Facets: map[string][]string{"type": {"SHELTER", "clinic"}}}
+	var got []string
+	for _, row := range query.Filter(tab) {
+		got = append(got, row.(facetRow).Company)
+	}
+	if want := []string{"Acme Shelter", "Bay Shelter"}; !slices.Equal(got, want) {
+		t.Errorf("Filter() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"disaster/apperr"
+	"disaster/components"
+	"disaster/geo"
+	"disaster/i18n"
+	"disaster/pages"
+	"disaster/ratelimit"
+	"disaster/snapshot"
+	"disaster/zones"
+)
+
+// maxZoneAddress caps the length of an address to look up
+const maxZoneAddress = 200
+
+// zoneLookupLimiter caps how many addresses a client can look up
+var zoneLookupLimiter = ratelimit.New(60, time.Hour)
+
+// HandleZonesPage looks up the zones reaching into the ZIP code of an
+// address, such as evacuation zones, and lists the resources tagged with
+// them. The only place known for an address is its ZIP code, so any zone
+// covering part of the ZIP code's area is listed, and the page says so.
+// Addresses are not geocoded or remembered.
+func HandleZonesPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	address := strings.TrimSpace(r.URL.Query().Get("address"))
+
+	meta := pageMeta(r, i18n.T(ctx, "Zone lookup")+" - mili.fit", "")
+	meta.NoIndex = address != ""
+	var (
+		zip     string
+		message string
+		matches []zones.Zone
+		groups  []components.SearchResultGroup
+	)
+	switch {
+	case address == "":
+	case utf8.RuneCountInString(address) > maxZoneAddress:
+		message = i18n.T(ctx, "Keep this under %d characters.", maxZoneAddress)
+	case !zoneLookupLimiter.Allow(clientIP(r)):
+		err := fmt.Errorf("too many zone lookups from %s", clientIP(r))
+		WriteError(w, r, apperr.Wrap(apperr.RateLimited, err, "You're doing that too often. Please try again later."))
+		return
+	default:
+		var area geo.ZIPArea
+		found := false
+		if zip, found = geo.ExtractZIP(address); found {
+			area, found = geo.ZIPs.Area(zip)
+		}
+		if !found {
+			zip = ""
+			message = i18n.T(ctx, "We couldn't find that address. Try its five-digit ZIP code.")
+			break
+		}
+		matches = zones.Near(area.Center, area.Radius)
+		groups = zoneResultGroups(ctx, snapshot.Current(ctx), matches)
+		log.Printf("Zone lookup matched %d zones", len(matches))
+	}
+
+	if err := pages.Zones(meta, address, zip, message, matches, groups).Render(ctx, w); err != nil {
+		log.Printf("Error rendering zones page: %v", err)
+	}
+}
+
+// zoneResultGroups returns the unexpired rows tagged with any of the zones,
+// grouped by tab
+func zoneResultGroups(ctx context.Context, snap *snapshot.Snapshot, matches []zones.Zone) []components.SearchResultGroup {
+	if len(matches) == 0 {
+		return nil
+	}
+	var groups []components.SearchResultGroup
+	now := time.Now()
+	for _, tab := range snap.Tabs {
+		if len(tab.Zones) == 0 {
+			continue
+		}
+		var rows []any
+		for _, row := range tab.Rows {
+			if taggedWithZone(tab.Zones[tab.RowID(row)], matches) && !tab.Expired(row, now) {
+				rows = append(rows, row)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		group, ok := newSearchResultGroup(ctx, snap, "", tab.SheetID+"/"+tab.TabName)
+		if !ok {
+			continue
+		}
+		group.Rows = rows
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// taggedWithZone reports whether any of a row's zone names is one of the
+// zones, ignoring case
+func taggedWithZone(names []string, matches []zones.Zone) bool {
+	for _, name := range names {
+		for _, zone := range matches {
+			if strings.EqualFold(name, zone.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
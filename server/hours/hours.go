@@ -0,0 +1,387 @@
+// Package hours reads opening hours written as free text, such as
+// "Mon-Fri 9am-5pm; Sat 10am-2pm", and tells whether a place is open at a
+// given time. Hours are taken to be in the time zone from TIME_ZONE.
+package hours
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // the container image has no zoneinfo
+
+	"github.com/jritsema/gotoolbox"
+)
+
+// Location is the time zone opening hours and expiry dates are written in
+var Location = loadLocation(gotoolbox.GetEnvWithDefault("TIME_ZONE", "America/Los_Angeles"))
+
+func loadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Hours: unknown TIME_ZONE %q, using UTC: %v", name, err)
+		return time.UTC
+	}
+	return loc
+}
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// ErrUnreadable is returned for hours that don't follow any known format
+var ErrUnreadable = errors.New("unreadable opening hours")
+
+// interval is a time a place is open, in minutes since midnight on Sunday.
+// Intervals never cross the end of the week.
+type interval struct {
+	start, end int
+}
+
+// Schedule is the weekly opening hours of a place. The zero Schedule is
+// never open.
+type Schedule struct {
+	intervals []interval // sorted and merged
+}
+
+var (
+	dayNames = map[string]time.Weekday{
+		"sunday": time.Sunday, "sun": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tues": time.Tuesday, "tue": time.Tuesday,
+		"wednesday": time.Wednesday, "weds": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thurs": time.Thursday, "thur": time.Thursday, "thu": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+	}
+	everyDay     = []time.Weekday{0, 1, 2, 3, 4, 5, 6}
+	day          = `(sundays?|sun|mondays?|mon|tuesdays?|tues|tue|wednesdays?|weds|wed|thursdays?|thurs|thur|thu|fridays?|fri|saturdays?|sat)`
+	clock        = `(\d{1,2})(?::(\d{2}))?\s*(am|pm|a|p)?`
+	tokenPattern = regexp.MustCompile(`\b` + clock + `\s*-\s*` + clock + `\b` +
+		`|\b(24/7|24 hours|24 hrs|24hrs|all day)\b` +
+		`|\b(weekdays|weekends|daily|every day|everyday|7 days|m\s*-\s*f)\b` +
+		`|\b` + day + `\b(?:\s*-\s*\b` + day + `\b)?` +
+		`|\b(closed)\b`)
+	// segmentSeparators split hours into parts read on their own, as in
+	// "9am-5pm Mon-Fri; Sat 10-2"
+	segmentSeparators = regexp.MustCompile(`[;|\n]`)
+)
+
+// runKind is what a run of tokens in opening hours says
+type runKind int
+
+const (
+	dayRun runKind = iota
+	hoursRun
+	closedRun
+)
+
+// run is consecutive tokens of one kind: days, as in "Mon, Wed", hours, as
+// in "9-12, 1-4", or "closed"
+type run struct {
+	kind   runKind
+	days   []time.Weekday
+	ranges []interval // minutes since midnight; ends past a day run overnight
+}
+
+// Parse reads opening hours such as "Mon-Fri 9am-5pm, Sat 10-2",
+// "9:00-17:00 M-F", "Daily 8:00-20:00", "24/7" or "Sun closed". Days can come
+// before or after their hours. Hours given without any days apply to every
+// day; times past midnight run into the next day. Hours it can't read, or
+// that say when a place is closed but never when it is open, are
+// ErrUnreadable, since guessing would tell visitors a place is open when it
+// isn't.
+func Parse(text string) (Schedule, error) {
+	normalized := strings.NewReplacer(
+		"–", "-", "—", "-", " to ", " - ", " thru ", " - ", " through ", " - ",
+		"a.m.", "am", "p.m.", "pm", "noon", "12pm", "midnight", "12am",
+	).Replace(strings.ToLower(text))
+
+	var intervals []interval
+	closed := make(map[time.Weekday]bool)
+	for _, segment := range segmentSeparators.Split(normalized, -1) {
+		runs, err := scan(segment)
+		if err != nil {
+			return Schedule{}, err
+		}
+		found, err := pair(runs, closed)
+		if err != nil {
+			return Schedule{}, err
+		}
+		intervals = append(intervals, found...)
+	}
+
+	// Days said to be closed anywhere take no hours, as in "Daily 9-5,
+	// closed Sunday"
+	open := intervals[:0]
+	for _, iv := range intervals {
+		if !closed[time.Weekday(iv.start/minutesPerDay)] {
+			open = append(open, iv)
+		}
+	}
+	if len(open) == 0 {
+		return Schedule{}, ErrUnreadable
+	}
+	return newSchedule(open), nil
+}
+
+// scan reads the tokens of one part of opening hours into runs
+func scan(segment string) ([]run, error) {
+	var runs []run
+	add := func(r run) {
+		if n := len(runs); n > 0 && runs[n-1].kind == r.kind {
+			runs[n-1].days = append(runs[n-1].days, r.days...)
+			runs[n-1].ranges = append(runs[n-1].ranges, r.ranges...)
+			return
+		}
+		// Runs get days appended, so they mustn't share everyDay's array
+		r.days = slices.Clone(r.days)
+		runs = append(runs, r)
+	}
+
+	for _, m := range tokenPattern.FindAllStringSubmatch(segment, -1) {
+		switch {
+		case m[1] != "":
+			start, end, ok := clockRange(m[1:7])
+			if !ok {
+				return nil, ErrUnreadable
+			}
+			add(run{kind: hoursRun, ranges: []interval{{start, end}}})
+		case m[7] != "":
+			add(run{kind: hoursRun, ranges: []interval{{0, minutesPerDay}}})
+		case m[8] != "":
+			switch {
+			case m[8] == "weekdays" || strings.HasPrefix(m[8], "m"):
+				add(run{kind: dayRun, days: everyDay[1:6]})
+			case m[8] == "weekends":
+				add(run{kind: dayRun, days: []time.Weekday{time.Saturday, time.Sunday}})
+			default:
+				add(run{kind: dayRun, days: everyDay})
+			}
+		case m[9] != "":
+			first := dayNames[strings.TrimSuffix(m[9], "s")]
+			last := dayNames[strings.TrimSuffix(m[10], "s")]
+			add(run{kind: dayRun, days: dayRange(first, last, m[10] != "")})
+		case m[11] != "":
+			add(run{kind: closedRun})
+		}
+	}
+	return runs, nil
+}
+
+// pair matches each run of hours or "closed" with the days next to it,
+// whether they come first, as in "Mon-Fri 9-5", or after, as in "9-5
+// Mon-Fri". It returns the open intervals and adds the days said to be
+// closed to closed. Hours with no days anywhere in the part apply to every
+// day; days or hours left without a partner make the hours unreadable.
+func pair(runs []run, closed map[time.Weekday]bool) ([]interval, error) {
+	var intervals []interval
+	apply := func(days []time.Weekday, r run) {
+		for _, d := range days {
+			if r.kind == closedRun {
+				closed[d] = true
+				continue
+			}
+			for _, iv := range r.ranges {
+				intervals = append(intervals, interval{int(d)*minutesPerDay + iv.start, int(d)*minutesPerDay + iv.end})
+			}
+		}
+	}
+
+	hasDays := false
+	for _, r := range runs {
+		hasDays = hasDays || r.kind == dayRun
+	}
+	if !hasDays {
+		for _, r := range runs {
+			// "Closed" without days, as in "closed holidays", says
+			// nothing about the week
+			if r.kind == hoursRun {
+				apply(everyDay, r)
+			}
+		}
+		return intervals, nil
+	}
+
+	for i := 0; i < len(runs); {
+		r := runs[i]
+		var next *run
+		if i+1 < len(runs) {
+			next = &runs[i+1]
+		}
+		switch {
+		case r.kind == dayRun && next != nil && next.kind != dayRun:
+			apply(r.days, *next)
+			i += 2
+		case r.kind != dayRun && next != nil && next.kind == dayRun:
+			apply(next.days, r)
+			i += 2
+		case r.kind == closedRun:
+			i++
+		default:
+			return nil, ErrUnreadable
+		}
+	}
+	return intervals, nil
+}
+
+// dayRange returns the days from first to last, wrapping around the end of
+// the week as in "Sat-Mon", or just first when there is no last day
+func dayRange(first, last time.Weekday, isRange bool) []time.Weekday {
+	if !isRange {
+		return []time.Weekday{first}
+	}
+	days := []time.Weekday{first}
+	for d := first; d != last; {
+		d = (d + 1) % 7
+		days = append(days, d)
+	}
+	return days
+}
+
+// clockRange reads the start and end of a time range in minutes since
+// midnight, from the hour, minute and am/pm of both. The end is after the
+// start, past midnight for ranges that run overnight.
+func clockRange(parts []string) (start, end int, ok bool) {
+	startHour, _ := strconv.Atoi(parts[0])
+	endHour, _ := strconv.Atoi(parts[3])
+	startMinute, _ := strconv.Atoi(parts[1])
+	endMinute, _ := strconv.Atoi(parts[4])
+	startSuffix, endSuffix := strings.TrimSuffix(parts[2], "m"), strings.TrimSuffix(parts[5], "m")
+	if startHour > 24 || endHour > 24 || startMinute > 59 || endMinute > 59 {
+		return 0, 0, false
+	}
+
+	twentyFourHour := startHour > 12 || endHour > 12 || strings.HasPrefix(parts[0], "0")
+	switch {
+	case startSuffix != "" || endSuffix != "":
+		if (startSuffix != "" && startHour > 12) || (endSuffix != "" && endHour > 12) {
+			return 0, 0, false
+		}
+		if endSuffix == "" {
+			endSuffix = startSuffix
+			if to24(endHour, endSuffix)*60+endMinute <= to24(startHour, startSuffix)*60+startMinute {
+				endSuffix = flip(endSuffix)
+			}
+		}
+		if startSuffix == "" {
+			startSuffix = endSuffix
+			if to24(startHour, startSuffix)*60+startMinute >= to24(endHour, endSuffix)*60+endMinute {
+				startSuffix = flip(startSuffix)
+			}
+		}
+		startHour, endHour = to24(startHour, startSuffix), to24(endHour, endSuffix)
+	case !twentyFourHour:
+		// Without am or pm, early hours are afternoon ones, as in "1-4",
+		// and an end before the start is in the afternoon, as in "9-5"
+		if startHour < 7 {
+			startHour += 12
+		}
+		if endHour*60+endMinute <= startHour*60+startMinute && endHour < 12 {
+			endHour += 12
+		}
+	}
+
+	start, end = startHour*60+startMinute, endHour*60+endMinute
+	if end <= start {
+		end += minutesPerDay
+	}
+	return start, end, true
+}
+
+// to24 converts an hour with an "a" or "p" suffix to 24-hour time
+func to24(hour int, suffix string) int {
+	hour %= 12
+	if suffix == "p" {
+		hour += 12
+	}
+	return hour
+}
+
+// flip swaps "a" for "p" and "p" for "a"
+func flip(suffix string) string {
+	if suffix == "a" {
+		return "p"
+	}
+	return "a"
+}
+
+// newSchedule sorts and merges intervals, splitting those that run past the
+// end of the week
+func newSchedule(intervals []interval) Schedule {
+	var split []interval
+	for _, iv := range intervals {
+		if iv.end > minutesPerWeek {
+			split = append(split, interval{0, iv.end - minutesPerWeek})
+			iv.end = minutesPerWeek
+		}
+		split = append(split, iv)
+	}
+	sort.Slice(split, func(i, j int) bool { return split[i].start < split[j].start })
+
+	var merged []interval
+	for _, iv := range split {
+		if n := len(merged); n > 0 && iv.start <= merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, iv.end)
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return Schedule{intervals: merged}
+}
+
+// Status is whether a place is open at a time, and when that changes
+type Status struct {
+	At         time.Time // the time the status is for, in Location
+	Open       bool
+	AlwaysOpen bool
+	// Change is when the place next closes when open, or opens when closed.
+	// It is zero when that never happens.
+	Change time.Time
+}
+
+// Status returns whether the schedule is open at t
+func (s Schedule) Status(t time.Time) Status {
+	t = t.In(Location).Truncate(time.Minute)
+	status := Status{At: t}
+	if len(s.intervals) == 0 {
+		return status
+	}
+	if len(s.intervals) == 1 && s.intervals[0] == (interval{0, minutesPerWeek}) {
+		status.Open, status.AlwaysOpen = true, true
+		return status
+	}
+
+	now := int(t.Weekday())*minutesPerDay + t.Hour()*60 + t.Minute()
+	at := func(minute int) time.Time {
+		return t.Add(time.Duration(minute-now) * time.Minute)
+	}
+	for i, iv := range s.intervals {
+		if now < iv.start {
+			status.Change = at(iv.start)
+			return status
+		}
+		if now < iv.end {
+			status.Open = true
+			end := iv.end
+			// Open past the end of the week when the week starts open
+			if end == minutesPerWeek && s.intervals[0].start == 0 && i != 0 {
+				end += s.intervals[0].end
+			}
+			status.Change = at(end)
+			return status
+		}
+	}
+	status.Change = at(s.intervals[0].start + minutesPerWeek)
+	return status
+}
+
+// IsOpen reports whether the schedule is open at t
+func (s Schedule) IsOpen(t time.Time) bool {
+	return s.Status(t).Open
+}
@@ -0,0 +1,135 @@
+package hours
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// at returns a time in the week of Monday, October 19, 2026
+func at(weekday time.Weekday, hour, minute int) time.Time {
+	day := 18 + int(weekday) // October 18, 2026 is a Sunday
+	return time.Date(2026, time.October, day, hour, minute, 0, 0, Location)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text   string
+		open   []time.Time
+		closed []time.Time
+	}{
+		{
+			text:   "Mon-Fri 9am-5pm",
+			open:   []time.Time{at(time.Monday, 9, 0), at(time.Friday, 16, 59)},
+			closed: []time.Time{at(time.Monday, 8, 59), at(time.Friday, 17, 0), at(time.Saturday, 12, 0)},
+		},
+		{
+			text:   "9am-5pm Mon-Fri",
+			open:   []time.Time{at(time.Wednesday, 12, 0)},
+			closed: []time.Time{at(time.Saturday, 12, 0), at(time.Sunday, 12, 0)},
+		},
+		{
+			text:   "Open 9-5 weekdays",
+			open:   []time.Time{at(time.Tuesday, 16, 30)},
+			closed: []time.Time{at(time.Saturday, 12, 0), at(time.Tuesday, 17, 30)},
+		},
+		{
+			text:   "9:00 - 17:00 M-F",
+			open:   []time.Time{at(time.Thursday, 9, 0)},
+			closed: []time.Time{at(time.Saturday, 12, 0)},
+		},
+		{
+			text:   "Mon-Fri 9-5, Sat 10-2",
+			open:   []time.Time{at(time.Monday, 10, 0), at(time.Saturday, 13, 0)},
+			closed: []time.Time{at(time.Saturday, 15, 0), at(time.Sunday, 12, 0)},
+		},
+		{
+			text:   "9am-5pm Mon-Fri; Sat 10am-2pm",
+			open:   []time.Time{at(time.Friday, 10, 0), at(time.Saturday, 11, 0)},
+			closed: []time.Time{at(time.Sunday, 11, 0)},
+		},
+		{
+			text:   "10-2 Sat, 9-5 Mon-Fri",
+			open:   []time.Time{at(time.Saturday, 11, 0), at(time.Monday, 16, 0)},
+			closed: []time.Time{at(time.Saturday, 16, 0)},
+		},
+		{
+			text:   "Sat 9-12, 1-4",
+			open:   []time.Time{at(time.Saturday, 9, 30), at(time.Saturday, 15, 0)},
+			closed: []time.Time{at(time.Saturday, 12, 30), at(time.Monday, 10, 0)},
+		},
+		{
+			text:   "Daily 8:00-20:00, closed Sundays",
+			open:   []time.Time{at(time.Saturday, 19, 0)},
+			closed: []time.Time{at(time.Sunday, 12, 0)},
+		},
+		{
+			text:   "Sun closed; Mon-Sat 10am to 6pm",
+			open:   []time.Time{at(time.Monday, 10, 0)},
+			closed: []time.Time{at(time.Sunday, 12, 0)},
+		},
+		{
+			text:   "Fri-Sat 8pm-2am",
+			open:   []time.Time{at(time.Saturday, 1, 0), at(time.Saturday, 21, 0)},
+			closed: []time.Time{at(time.Friday, 3, 0), at(time.Sunday, 3, 0)},
+		},
+		{
+			text: "24/7",
+			open: []time.Time{at(time.Sunday, 0, 0), at(time.Wednesday, 3, 0)},
+		},
+		{
+			text:   "9-5",
+			open:   []time.Time{at(time.Sunday, 12, 0)},
+			closed: []time.Time{at(time.Sunday, 18, 0)},
+		},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.text, err)
+			continue
+		}
+		for _, when := range tt.open {
+			if !schedule.IsOpen(when) {
+				t.Errorf("Parse(%q) is closed %s, want open", tt.text, when.Format("Mon 15:04"))
+			}
+		}
+		for _, when := range tt.closed {
+			if schedule.IsOpen(when) {
+				t.Errorf("Parse(%q) is open %s, want closed", tt.text, when.Format("Mon 15:04"))
+			}
+		}
+	}
+}
+
+func TestParseUnreadable(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"By appointment",
+		"Closed Sundays",
+		"Sun closed",
+		"Mon-Fri",
+		"Mon-Fri 9-5, Sat",
+		"9-5 weekdays, 10-2",
+		"25-26",
+	} {
+		if _, err := Parse(text); !errors.Is(err, ErrUnreadable) {
+			t.Errorf("Parse(%q) error = %v, want ErrUnreadable", text, err)
+		}
+	}
+}
+
+func TestStatusChange(t *testing.T) {
+	schedule, err := Parse("Mon-Fri 9am-5pm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := schedule.Status(at(time.Friday, 18, 0))
+	if status.Open || !status.Change.Equal(at(time.Monday, 9, 0).AddDate(0, 0, 7)) {
+		t.Errorf("Status on Friday evening = %+v, want closed until Monday 9am", status)
+	}
+	status = schedule.Status(at(time.Monday, 10, 0))
+	if !status.Open || !status.Change.Equal(at(time.Monday, 17, 0)) {
+		t.Errorf("Status on Monday morning = %+v, want open until 5pm", status)
+	}
+}
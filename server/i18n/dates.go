@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// shortMonths are the abbreviated month names of languages that don't use
+// Go's English ones, January first
+var shortMonths = map[language.Tag][12]string{
+	language.Spanish: {"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+	language.Hindi:   {"जन॰", "फ़र॰", "मार्च", "अप्रैल", "मई", "जून", "जुल॰", "अग॰", "सित॰", "अक्तू॰", "नव॰", "दिस॰"},
+}
+
+// weekdays are the weekday names of languages that don't use Go's English
+// ones, Sunday first
+var weekdays = map[language.Tag][7]string{
+	language.Spanish: {"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+	language.Hindi:   {"रविवार", "सोमवार", "मंगलवार", "बुधवार", "गुरुवार", "शुक्रवार", "शनिवार"},
+}
+
+// FormatClock formats a time of day the way the language of ctx writes it,
+// e.g. "5pm" or "9:30am" in English and "17:00" in other languages
+func FormatClock(ctx context.Context, t time.Time) string {
+	if Language(ctx) != language.English {
+		return t.Format("15:04")
+	}
+	if t.Minute() == 0 {
+		return t.Format("3pm")
+	}
+	return t.Format("3:04pm")
+}
+
+// FormatWeekday returns the name of t's weekday in the language of ctx
+func FormatWeekday(ctx context.Context, t time.Time) string {
+	names, ok := weekdays[Language(ctx)]
+	if !ok {
+		return t.Weekday().String()
+	}
+	return names[t.Weekday()]
+}
+
+// FormatDate formats a date the way the language of ctx writes a medium
+// length date, e.g. "Jan 2, 2006" in English and "2 ene 2006" in Spanish
+func FormatDate(ctx context.Context, t time.Time) string {
+	months, ok := shortMonths[Language(ctx)]
+	if !ok {
+		return t.Format("Jan 2, 2006")
+	}
+	return fmt.Sprintf("%d %s %d", t.Day(), months[t.Month()-1], t.Year())
+}
+
+// Ago describes how long before now t was in the language of ctx, e.g.
+// "3 hours ago". Times over a week ago are given as a date.
+func Ago(ctx context.Context, t time.Time) string {
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return T(ctx, "just now")
+	case elapsed < 2*time.Minute:
+		return T(ctx, "1 minute ago")
+	case elapsed < time.Hour:
+		return T(ctx, "%d minutes ago", int(elapsed/time.Minute))
+	case elapsed < 2*time.Hour:
+		return T(ctx, "1 hour ago")
+	case elapsed < 24*time.Hour:
+		return T(ctx, "%d hours ago", int(elapsed/time.Hour))
+	case elapsed < 48*time.Hour:
+		return T(ctx, "1 day ago")
+	case elapsed < 7*24*time.Hour:
+		return T(ctx, "%d days ago", int(elapsed/(24*time.Hour)))
+	default:
+		return T(ctx, "on %s", FormatDate(ctx, t))
+	}
+}
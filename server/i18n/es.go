@@ -0,0 +1,221 @@
+package i18n
+
+// es is the Spanish catalog
+var es = map[string]string{
+	// Cards
+	"Category: %s":            "Categoría: %s",
+	"Added: %s":               "Añadido: %s",
+	"Code: %s":                "Código: %s",
+	"Copy to clipboard":       "Copiar al portapapeles",
+	"Products Available:":     "Productos disponibles:",
+	"Where:":                  "Dónde:",
+	"Open in Google Maps":     "Abrir en Google Maps",
+	"How to get in touch: %s": "Cómo contactar: %s",
+	"Learn More":              "Más información",
+	"Additional Information:": "Información adicional:",
+	"Link to this card":       "Enlace a esta tarjeta",
+
+	// Tab views
+	"Back to tabs":                "Volver a las pestañas",
+	"%d results":                  "%d resultados",
+	"Filter...":                   "Filtrar...",
+	"Sheet order":                 "Orden de la hoja",
+	"Apply":                       "Aplicar",
+	"Load more":                   "Cargar más",
+	"%s (newest first)":           "%s (más recientes primero)",
+	"%s (oldest first)":           "%s (más antiguos primero)",
+	"%s (A-Z)":                    "%s (de la A a la Z)",
+	"%s (Z-A)":                    "%s (de la Z a la A)",
+	"View data":                   "Ver datos",
+	"No data view configured":     "Sin vista de datos configurada",
+	"See every card in this list": "Ver todas las tarjetas de esta lista",
+
+	// Home page and search
+	"Loading...": "Cargando...",
+	"Open page":  "Abrir página",
+	"There are no resources in this category yet.": "Todavía no hay recursos en esta categoría.",
+	"Pick a category to see its resources.":        "Elige una categoría para ver sus recursos.",
+	"Search resources...":                          "Buscar recursos...",
+	"Search":                                       "Buscar",
+	`No results for "%s"`:                          `No hay resultados para "%s"`,
+	"%d more":                                      "%d más",
+	"Resources":                                    "Recursos",
+
+	// Suggestions
+	"Suggest a resource": "Sugerir un recurso",
+	"Website (optional)": "Sitio web (opcional)",
+	"Send suggestion":    "Enviar sugerencia",
+	"Suggest another":    "Sugerir otro",
+	"Back to the list":   "Volver a la lista",
+	"Thanks! Your suggestion will be listed once a moderator has checked it.":                      "¡Gracias! Tu sugerencia aparecerá en cuanto un moderador la revise.",
+	"Know of something that should be on this list? Tell us about it and a moderator will add it.": "¿Conoces algo que debería estar en esta lista? Cuéntanos y un moderador lo añadirá.",
+	"This field is required.":                        "Este campo es obligatorio.",
+	"Keep this under %d characters.":                 "Usa menos de %d caracteres.",
+	"Enter a link starting with http:// or https://": "Introduce un enlace que empiece por http:// o https://",
+	"Enter a date like 2025-01-31.":                  "Introduce una fecha como 2025-01-31.",
+
+	// Reports
+	"Report a problem":                         "Informar de un problema",
+	"What's wrong?":                            "¿Qué está mal?",
+	"Outdated, such as an expired code":        "Desactualizado, como un código caducado",
+	"Incorrect details":                        "Datos incorrectos",
+	"No longer available":                      "Ya no está disponible",
+	"Something else":                           "Otra cosa",
+	"Anything else we should know? (optional)": "¿Algo más que debamos saber? (opcional)",
+	"Comment":     "Comentario",
+	"Send report": "Enviar informe",
+	"Thanks for letting us know. We'll check this card.": "Gracias por avisarnos. Revisaremos esta tarjeta.",
+	"Reported as possibly outdated":                      "Señalado como posiblemente desactualizado",
+
+	// Confirmations
+	"Confirmed working %s":    "Confirmado que funciona %s",
+	"Not confirmed yet":       "Aún sin confirmar",
+	"reported not working %s": "señalado que no funciona %s",
+	"It worked":               "Funcionó",
+	"It didn't work":          "No funcionó",
+	"Most recently confirmed": "Confirmados más recientemente",
+	"just now":                "ahora mismo",
+	"1 minute ago":            "hace 1 minuto",
+	"%d minutes ago":          "hace %d minutos",
+	"1 hour ago":              "hace 1 hora",
+	"%d hours ago":            "hace %d horas",
+	"1 day ago":               "hace 1 día",
+	"%d days ago":             "hace %d días",
+	"on %s":                   "el %s",
+
+	// Saved plans
+	"Save to my plan":              "Guardar en mi plan",
+	"Saved to my plan":             "Guardado en mi plan",
+	"My plan":                      "Mi plan",
+	"Shared plan":                  "Plan compartido",
+	"Print":                        "Imprimir",
+	"Get a link to share":          "Obtener un enlace para compartir",
+	"Use this plan on this device": "Usar este plan en este dispositivo",
+	"Anyone with the link can see this plan:":                                            "Cualquiera con el enlace puede ver este plan:",
+	"Your plan is kept on this device. Get a link to open it elsewhere or share it.":     "Tu plan se guarda en este dispositivo. Obtén un enlace para abrirlo en otro lugar o compartirlo.",
+	"You haven't saved any cards yet. Use \"Save to my plan\" on a card to add it here.": "Todavía no has guardado ninguna tarjeta. Usa \"Guardar en mi plan\" en una tarjeta para añadirla aquí.",
+	"No longer listed.":                       "Ya no aparece en la lista.",
+	"This offer has expired.":                 "Esta oferta ha vencido.",
+	"Changed since you saved it.":             "Ha cambiado desde que lo guardaste.",
+	"This card couldn't be loaded right now.": "No se pudo cargar esta tarjeta en este momento.",
+
+	// Digests
+	"Email updates aren't available on mili.fit right now.": "Las novedades por correo no están disponibles en mili.fit en este momento.",
+	"Get updates by email": "Recibe novedades por correo",
+	"We'll email you a digest of new and changed listings. You can unsubscribe at any time.": "Te enviaremos por correo un resumen de los anuncios nuevos y modificados. Puedes darte de baja en cualquier momento.",
+	"Email address":    "Correo electrónico",
+	"How often":        "Frecuencia",
+	"Daily":            "Diario",
+	"Weekly":           "Semanal",
+	"daily":            "diario",
+	"weekly":           "semanal",
+	"Subscribe":        "Suscribirse",
+	"Check your email": "Revisa tu correo",
+	"We sent a link to %s. Open it to confirm your subscription; digests start once you do.": "Enviamos un enlace a %s. Ábrelo para confirmar tu suscripción; los resúmenes empezarán cuando lo hagas.",
+	"Subscription confirmed":                                    "Suscripción confirmada",
+	"You'll get a %s digest of new and changed listings in %s.": "Recibirás un resumen %s de los anuncios nuevos y modificados en %s.",
+	"You're unsubscribed":                                       "Te has dado de baja",
+	"You won't get any more emails from this subscription.":     "No recibirás más correos de esta suscripción.",
+	"Unsubscribe":                          "Darse de baja",
+	"Stop sending %s digests of %s to %s?": "¿Dejar de enviar resúmenes %s de %s a %s?",
+	"Confirm your mili.fit subscription":   "Confirma tu suscripción a mili.fit",
+	"Confirm that you want a %s digest of new and changed listings in %s on mili.fit:": "Confirma que quieres un resumen %s de los anuncios nuevos y modificados en %s en mili.fit:",
+	"If you didn't ask for this, ignore this email and you won't hear from us again.":  "Si no lo pediste, ignora este correo y no volverás a saber de nosotros.",
+	"%d new and changed listings in %s":                                                "%d anuncios nuevos y modificados en %s",
+	"New and changed listings in %s since %s:":                                         "Anuncios nuevos y modificados en %s desde el %s:",
+	"New":     "Nuevo",
+	"Changed": "Modificado",
+	"You're getting this %s digest because you subscribed to %s on mili.fit.": "Recibes este resumen %s porque te suscribiste a %s en mili.fit.",
+	"Unsubscribe: %s": "Darse de baja: %s",
+
+	// Locations
+	"Nearest first": "Más cercanos primero",
+	"Your ZIP code": "Tu código postal",
+	"%.1f mi away":  "a %.1f mi",
+
+	// Opening hours
+	"Open now":           "Abierto ahora",
+	"Open 24 hours":      "Abierto las 24 horas",
+	"Closed":             "Cerrado",
+	"Closes at %s":       "Cierra a las %s",
+	"Opens at %s":        "Abre a las %s",
+	"Closes tomorrow %s": "Cierra mañana a las %s",
+	"Opens tomorrow %s":  "Abre mañana a las %s",
+	"Closes %[1]s %[2]s": "Cierra el %[1]s a las %[2]s",
+	"Opens %[1]s %[2]s":  "Abre el %[1]s a las %[2]s",
+	"Open now · %s":      "Abierto ahora · %s",
+	"Closed · %s":        "Cerrado · %s",
+
+	// Expiry
+	"Current":              "Vigentes",
+	"Expired":              "Vencidos",
+	"Expired %s":           "Venció el %s",
+	"Expires today":        "Vence hoy",
+	"Expires tomorrow":     "Vence mañana",
+	"Expires in %d days":   "Vence en %d días",
+	"Not updated since %s": "Sin actualizar desde el %s",
+
+	// Companies
+	"All %d offers from this company":                     "Las %d ofertas de esta empresa",
+	"Listed in %d places":                                 "Aparece en %d lugares",
+	"Every offer from %s listed on mili.fit":              "Todas las ofertas de %s en mili.fit",
+	"This company's offers are no longer listed together": "Las ofertas de esta empresa ya no aparecen juntas",
+
+	// Zones
+	"Zone lookup":               "Consulta de zonas",
+	"Am I in an affected zone?": "¿Estoy en una zona afectada?",
+	"Address or ZIP code":       "Dirección o código postal",
+	"Check":                     "Consultar",
+	"Matched by the area of ZIP code %s, not your exact address. A zone listed may cover only part of it, so check the official map. Always follow the instructions of local officials.": "Coincidencia por el área del código postal %s, no por tu dirección exacta. Una zona de la lista puede cubrir solo una parte, así que consulta el mapa oficial. Sigue siempre las instrucciones de las autoridades locales.",
+	"None of the zones we know of reach ZIP code %s.":             "Ninguna de las zonas que conocemos llega al código postal %s.",
+	"Official information":                                        "Información oficial",
+	"Resources for these zones":                                   "Recursos para estas zonas",
+	"No resources are listed for these zones yet.":                "Todavía no hay recursos para estas zonas.",
+	"We couldn't find that address. Try its five-digit ZIP code.": "No encontramos esa dirección. Prueba con su código postal de cinco dígitos.",
+
+	// Navigation
+	"Breadcrumb": "Ruta de navegación",
+	"Language":   "Idioma",
+	"Home":       "Inicio",
+
+	// Errors
+	"Request ID %s":                       "ID de solicitud %s",
+	"Try again":                           "Reintentar",
+	"Something went wrong":                "Algo salió mal",
+	"Not found":                           "No encontrado",
+	"Temporarily unavailable":             "No disponible temporalmente",
+	"Invalid request":                     "Solicitud no válida",
+	"An unexpected error occurred.":       "Se produjo un error inesperado.",
+	"This page isn't set up on mili.fit.": "Esta página no está configurada en mili.fit.",
+	"This page doesn't exist.":            "Esta página no existe.",
+	"The spreadsheet this data comes from can't be reached right now.":      "No se puede acceder ahora a la hoja de cálculo de la que provienen estos datos.",
+	"The request was malformed.":                                            "La solicitud no tiene el formato correcto.",
+	"Google Sheets is unavailable right now":                                "Google Sheets no está disponible en este momento",
+	"This card is no longer listed":                                         "Esta tarjeta ya no aparece en la lista",
+	"Tab not configured":                                                    "Pestaña no configurada",
+	"Sheet not configured":                                                  "Hoja no configurada",
+	"Too many requests":                                                     "Demasiadas solicitudes",
+	"Sign in required":                                                      "Inicia sesión",
+	"Not allowed":                                                           "No permitido",
+	"You're doing that too often. Please try again later.":                  "Lo estás haciendo con demasiada frecuencia. Inténtalo de nuevo más tarde.",
+	"You need to sign in to see this page.":                                 "Tienes que iniciar sesión para ver esta página.",
+	"You don't have access to this page.":                                   "No tienes acceso a esta página.",
+	"You've sent a lot of suggestions. Please try again in an hour.":        "Has enviado muchas sugerencias. Inténtalo de nuevo dentro de una hora.",
+	"The form couldn't be read.":                                            "No se pudo leer el formulario.",
+	"This submission is no longer waiting for review":                       "Esta sugerencia ya no está pendiente de revisión",
+	"Pick what's wrong with this card.":                                     "Elige qué está mal en esta tarjeta.",
+	"These cards can't be confirmed":                                        "Estas tarjetas no se pueden confirmar",
+	"Say whether it worked for you.":                                        "Indica si te funcionó.",
+	"This plan doesn't exist or has expired":                                "Este plan no existe o ha caducado",
+	"The saved list couldn't be read.":                                      "No se pudo leer la lista guardada.",
+	"Lists can have at most %d cards.":                                      "Las listas pueden tener como máximo %d tarjetas.",
+	"Enter a valid email address.":                                          "Introduce un correo electrónico válido.",
+	"Choose daily or weekly.":                                               "Elige diario o semanal.",
+	"This subscription link has expired or was already used to unsubscribe": "Este enlace de suscripción ha caducado o ya se usó para darse de baja",
+	"This sign-in link has expired or was already used.":                    "Este enlace de inicio de sesión ha caducado o ya se usó.",
+	"Signing in didn't work. Please try again.":                             "No se pudo iniciar sesión. Inténtalo de nuevo.",
+	"The sign-in provider is unavailable right now":                         "El proveedor de inicio de sesión no está disponible en este momento",
+	"Enter a 5-digit ZIP code.":                                             "Introduce un código postal de 5 dígitos.",
+	"We don't know where ZIP code %s is yet.":                               "Todavía no sabemos dónde está el código postal %s.",
+	"Enter a ZIP code to sort by distance.":                                 "Introduce un código postal para ordenar por distancia.",
+}
@@ -0,0 +1,221 @@
+package i18n
+
+// hi is the Hindi catalog
+var hi = map[string]string{
+	// Cards
+	"Category: %s":            "श्रेणी: %s",
+	"Added: %s":               "जोड़ा गया: %s",
+	"Code: %s":                "कोड: %s",
+	"Copy to clipboard":       "क्लिपबोर्ड पर कॉपी करें",
+	"Products Available:":     "उपलब्ध उत्पाद:",
+	"Where:":                  "कहाँ:",
+	"Open in Google Maps":     "Google Maps में खोलें",
+	"How to get in touch: %s": "संपर्क कैसे करें: %s",
+	"Learn More":              "और जानें",
+	"Additional Information:": "अतिरिक्त जानकारी:",
+	"Link to this card":       "इस कार्ड का लिंक",
+
+	// Tab views
+	"Back to tabs":                "टैब पर वापस जाएँ",
+	"%d results":                  "%d परिणाम",
+	"Filter...":                   "फ़िल्टर करें...",
+	"Sheet order":                 "शीट का क्रम",
+	"Apply":                       "लागू करें",
+	"Load more":                   "और लोड करें",
+	"%s (newest first)":           "%s (नए पहले)",
+	"%s (oldest first)":           "%s (पुराने पहले)",
+	"%s (A-Z)":                    "%s (A से Z)",
+	"%s (Z-A)":                    "%s (Z से A)",
+	"View data":                   "डेटा देखें",
+	"No data view configured":     "कोई डेटा व्यू सेट नहीं है",
+	"See every card in this list": "इस सूची के सभी कार्ड देखें",
+
+	// Home page and search
+	"Loading...": "लोड हो रहा है...",
+	"Open page":  "पेज खोलें",
+	"There are no resources in this category yet.": "इस श्रेणी में अभी कोई संसाधन नहीं है।",
+	"Pick a category to see its resources.":        "संसाधन देखने के लिए कोई श्रेणी चुनें।",
+	"Search resources...":                          "संसाधन खोजें...",
+	"Search":                                       "खोजें",
+	`No results for "%s"`:                          `"%s" के लिए कोई परिणाम नहीं`,
+	"%d more":                                      "%d और",
+	"Resources":                                    "संसाधन",
+
+	// Suggestions
+	"Suggest a resource": "कोई संसाधन सुझाएँ",
+	"Website (optional)": "वेबसाइट (वैकल्पिक)",
+	"Send suggestion":    "सुझाव भेजें",
+	"Suggest another":    "एक और सुझाएँ",
+	"Back to the list":   "सूची पर वापस जाएँ",
+	"Thanks! Your suggestion will be listed once a moderator has checked it.":                      "धन्यवाद! मॉडरेटर के जाँचने के बाद आपका सुझाव सूची में दिखेगा।",
+	"Know of something that should be on this list? Tell us about it and a moderator will add it.": "क्या आप कुछ ऐसा जानते हैं जो इस सूची में होना चाहिए? हमें बताएँ, मॉडरेटर उसे जोड़ देंगे।",
+	"This field is required.":                        "यह फ़ील्ड ज़रूरी है।",
+	"Keep this under %d characters.":                 "इसे %d अक्षरों से कम रखें।",
+	"Enter a link starting with http:// or https://": "http:// या https:// से शुरू होने वाला लिंक डालें",
+	"Enter a date like 2025-01-31.":                  "2025-01-31 जैसी तारीख डालें।",
+
+	// Reports
+	"Report a problem":                         "समस्या बताएँ",
+	"What's wrong?":                            "क्या गलत है?",
+	"Outdated, such as an expired code":        "पुराना, जैसे कि समाप्त कोड",
+	"Incorrect details":                        "गलत जानकारी",
+	"No longer available":                      "अब उपलब्ध नहीं",
+	"Something else":                           "कुछ और",
+	"Anything else we should know? (optional)": "कुछ और जो हमें पता होना चाहिए? (वैकल्पिक)",
+	"Comment":     "टिप्पणी",
+	"Send report": "रिपोर्ट भेजें",
+	"Thanks for letting us know. We'll check this card.": "बताने के लिए धन्यवाद। हम इस कार्ड की जाँच करेंगे।",
+	"Reported as possibly outdated":                      "संभवतः पुराना होने की सूचना मिली है",
+
+	// Confirmations
+	"Confirmed working %s":    "काम करने की पुष्टि %s",
+	"Not confirmed yet":       "अभी तक पुष्टि नहीं हुई",
+	"reported not working %s": "काम न करने की सूचना %s",
+	"It worked":               "काम किया",
+	"It didn't work":          "काम नहीं किया",
+	"Most recently confirmed": "हाल ही में पुष्टि किए गए",
+	"just now":                "अभी-अभी",
+	"1 minute ago":            "1 मिनट पहले",
+	"%d minutes ago":          "%d मिनट पहले",
+	"1 hour ago":              "1 घंटा पहले",
+	"%d hours ago":            "%d घंटे पहले",
+	"1 day ago":               "1 दिन पहले",
+	"%d days ago":             "%d दिन पहले",
+	"on %s":                   "%s को",
+
+	// Saved plans
+	"Save to my plan":              "मेरी योजना में सहेजें",
+	"Saved to my plan":             "मेरी योजना में सहेजा गया",
+	"My plan":                      "मेरी योजना",
+	"Shared plan":                  "साझा योजना",
+	"Print":                        "प्रिंट करें",
+	"Get a link to share":          "साझा करने के लिए लिंक पाएँ",
+	"Use this plan on this device": "इस डिवाइस पर यह योजना इस्तेमाल करें",
+	"Anyone with the link can see this plan:":                                            "लिंक वाला कोई भी व्यक्ति यह योजना देख सकता है:",
+	"Your plan is kept on this device. Get a link to open it elsewhere or share it.":     "आपकी योजना इसी डिवाइस पर रखी गई है। इसे कहीं और खोलने या साझा करने के लिए लिंक पाएँ।",
+	"You haven't saved any cards yet. Use \"Save to my plan\" on a card to add it here.": "आपने अभी तक कोई कार्ड नहीं सहेजा है। किसी कार्ड को यहाँ जोड़ने के लिए \"मेरी योजना में सहेजें\" का इस्तेमाल करें।",
+	"No longer listed.":                       "अब सूची में नहीं है।",
+	"This offer has expired.":                 "यह ऑफ़र समाप्त हो गया है।",
+	"Changed since you saved it.":             "आपके सहेजने के बाद से इसमें बदलाव हुआ है।",
+	"This card couldn't be loaded right now.": "यह कार्ड अभी लोड नहीं हो सका।",
+
+	// Digests
+	"Email updates aren't available on mili.fit right now.": "ईमेल अपडेट अभी mili.fit पर उपलब्ध नहीं हैं।",
+	"Get updates by email": "ईमेल से अपडेट पाएँ",
+	"We'll email you a digest of new and changed listings. You can unsubscribe at any time.": "हम आपको नई और बदली गई लिस्टिंग का सारांश ईमेल करेंगे। आप कभी भी सदस्यता छोड़ सकते हैं।",
+	"Email address":    "ईमेल पता",
+	"How often":        "कितनी बार",
+	"Daily":            "रोज़ाना",
+	"Weekly":           "साप्ताहिक",
+	"daily":            "दैनिक",
+	"weekly":           "साप्ताहिक",
+	"Subscribe":        "सदस्यता लें",
+	"Check your email": "अपना ईमेल देखें",
+	"We sent a link to %s. Open it to confirm your subscription; digests start once you do.": "हमने %s पर एक लिंक भेजा है। अपनी सदस्यता की पुष्टि के लिए इसे खोलें; उसके बाद सारांश आने लगेंगे।",
+	"Subscription confirmed":                                    "सदस्यता की पुष्टि हो गई",
+	"You'll get a %s digest of new and changed listings in %s.": "आपको %[2]s में नई और बदली गई लिस्टिंग का %[1]s सारांश मिलेगा।",
+	"You're unsubscribed":                                       "आपकी सदस्यता समाप्त हो गई",
+	"You won't get any more emails from this subscription.":     "इस सदस्यता से आपको अब कोई ईमेल नहीं मिलेगा।",
+	"Unsubscribe":                          "सदस्यता छोड़ें",
+	"Stop sending %s digests of %s to %s?": "%[3]s पर %[2]s के %[1]s सारांश भेजना बंद करें?",
+	"Confirm your mili.fit subscription":   "अपनी mili.fit सदस्यता की पुष्टि करें",
+	"Confirm that you want a %s digest of new and changed listings in %s on mili.fit:": "पुष्टि करें कि आप mili.fit पर %[2]s में नई और बदली गई लिस्टिंग का %[1]s सारांश चाहते हैं:",
+	"If you didn't ask for this, ignore this email and you won't hear from us again.":  "अगर आपने यह नहीं माँगा था, तो इस ईमेल को अनदेखा करें, आपको हमसे फिर कोई ईमेल नहीं मिलेगा।",
+	"%d new and changed listings in %s":                                                "%[2]s में %[1]d नई और बदली गई लिस्टिंग",
+	"New and changed listings in %s since %s:":                                         "%[2]s के बाद से %[1]s में नई और बदली गई लिस्टिंग:",
+	"New":     "नई",
+	"Changed": "बदली गई",
+	"You're getting this %s digest because you subscribed to %s on mili.fit.": "आपको यह %[1]s सारांश इसलिए मिल रहा है क्योंकि आपने mili.fit पर %[2]s की सदस्यता ली है।",
+	"Unsubscribe: %s": "सदस्यता छोड़ें: %s",
+
+	// Locations
+	"Nearest first": "सबसे नज़दीकी पहले",
+	"Your ZIP code": "आपका ZIP कोड",
+	"%.1f mi away":  "%.1f मील दूर",
+
+	// Opening hours
+	"Open now":           "अभी खुला है",
+	"Open 24 hours":      "24 घंटे खुला",
+	"Closed":             "बंद है",
+	"Closes at %s":       "%s बजे बंद होगा",
+	"Opens at %s":        "%s बजे खुलेगा",
+	"Closes tomorrow %s": "कल %s बजे बंद होगा",
+	"Opens tomorrow %s":  "कल %s बजे खुलेगा",
+	"Closes %[1]s %[2]s": "%[1]s को %[2]s बजे बंद होगा",
+	"Opens %[1]s %[2]s":  "%[1]s को %[2]s बजे खुलेगा",
+	"Open now · %s":      "अभी खुला है · %s",
+	"Closed · %s":        "बंद है · %s",
+
+	// Expiry
+	"Current":              "मौजूदा",
+	"Expired":              "समाप्त",
+	"Expired %s":           "%s को समाप्त हुआ",
+	"Expires today":        "आज समाप्त होगा",
+	"Expires tomorrow":     "कल समाप्त होगा",
+	"Expires in %d days":   "%d दिनों में समाप्त होगा",
+	"Not updated since %s": "%s से अपडेट नहीं हुआ",
+
+	// Companies
+	"All %d offers from this company":                     "इस कंपनी के सभी %d ऑफ़र",
+	"Listed in %d places":                                 "%d जगहों पर सूचीबद्ध",
+	"Every offer from %s listed on mili.fit":              "mili.fit पर सूचीबद्ध %s के सभी ऑफ़र",
+	"This company's offers are no longer listed together": "इस कंपनी के ऑफ़र अब एक साथ सूचीबद्ध नहीं हैं",
+
+	// Zones
+	"Zone lookup":               "ज़ोन खोजें",
+	"Am I in an affected zone?": "क्या मैं किसी प्रभावित ज़ोन में हूँ?",
+	"Address or ZIP code":       "पता या ZIP कोड",
+	"Check":                     "जाँचें",
+	"Matched by the area of ZIP code %s, not your exact address. A zone listed may cover only part of it, so check the official map. Always follow the instructions of local officials.": "यह मिलान आपके सटीक पते से नहीं, ZIP कोड %s के क्षेत्र से किया गया है। सूची का कोई ज़ोन उसके केवल एक हिस्से को ढक सकता है, इसलिए आधिकारिक नक्शा देखें। हमेशा स्थानीय अधिकारियों के निर्देशों का पालन करें।",
+	"None of the zones we know of reach ZIP code %s.":             "हमारी जानकारी का कोई भी ज़ोन ZIP कोड %s तक नहीं पहुँचता।",
+	"Official information":                                        "आधिकारिक जानकारी",
+	"Resources for these zones":                                   "इन ज़ोन के लिए संसाधन",
+	"No resources are listed for these zones yet.":                "इन ज़ोन के लिए अभी कोई संसाधन नहीं हैं।",
+	"We couldn't find that address. Try its five-digit ZIP code.": "हमें वह पता नहीं मिला। उसका पाँच अंकों वाला ZIP कोड आज़माएँ।",
+
+	// Navigation
+	"Breadcrumb": "ब्रेडक्रम्ब",
+	"Language":   "भाषा",
+	"Home":       "होम",
+
+	// Errors
+	"Request ID %s":                       "अनुरोध आईडी %s",
+	"Try again":                           "फिर से कोशिश करें",
+	"Something went wrong":                "कुछ गलत हो गया",
+	"Not found":                           "नहीं मिला",
+	"Temporarily unavailable":             "अस्थायी रूप से उपलब्ध नहीं",
+	"Invalid request":                     "अमान्य अनुरोध",
+	"An unexpected error occurred.":       "एक अनपेक्षित त्रुटि हुई।",
+	"This page isn't set up on mili.fit.": "यह पेज mili.fit पर सेट नहीं है।",
+	"This page doesn't exist.":            "यह पेज मौजूद नहीं है।",
+	"The spreadsheet this data comes from can't be reached right now.":      "जिस स्प्रेडशीट से यह डेटा आता है, वह अभी उपलब्ध नहीं है।",
+	"The request was malformed.":                                            "अनुरोध सही प्रारूप में नहीं था।",
+	"Google Sheets is unavailable right now":                                "Google Sheets अभी उपलब्ध नहीं है",
+	"This card is no longer listed":                                         "यह कार्ड अब सूची में नहीं है",
+	"Tab not configured":                                                    "टैब कॉन्फ़िगर नहीं है",
+	"Sheet not configured":                                                  "शीट कॉन्फ़िगर नहीं है",
+	"Too many requests":                                                     "बहुत सारे अनुरोध",
+	"Sign in required":                                                      "साइन इन ज़रूरी है",
+	"Not allowed":                                                           "अनुमति नहीं है",
+	"You're doing that too often. Please try again later.":                  "आप यह बहुत बार कर रहे हैं। कृपया बाद में फिर कोशिश करें।",
+	"You need to sign in to see this page.":                                 "यह पेज देखने के लिए साइन इन करें।",
+	"You don't have access to this page.":                                   "आपके पास इस पेज की अनुमति नहीं है।",
+	"You've sent a lot of suggestions. Please try again in an hour.":        "आपने बहुत सारे सुझाव भेजे हैं। कृपया एक घंटे बाद फिर कोशिश करें।",
+	"The form couldn't be read.":                                            "फ़ॉर्म पढ़ा नहीं जा सका।",
+	"This submission is no longer waiting for review":                       "यह सुझाव अब समीक्षा की प्रतीक्षा में नहीं है",
+	"Pick what's wrong with this card.":                                     "चुनें कि इस कार्ड में क्या गलत है।",
+	"These cards can't be confirmed":                                        "इन कार्डों की पुष्टि नहीं की जा सकती",
+	"Say whether it worked for you.":                                        "बताएँ कि यह आपके लिए काम किया या नहीं।",
+	"This plan doesn't exist or has expired":                                "यह योजना मौजूद नहीं है या इसकी अवधि समाप्त हो गई है",
+	"The saved list couldn't be read.":                                      "सहेजी गई सूची पढ़ी नहीं जा सकी।",
+	"Lists can have at most %d cards.":                                      "सूचियों में अधिकतम %d कार्ड हो सकते हैं।",
+	"Enter a valid email address.":                                          "मान्य ईमेल पता डालें।",
+	"Choose daily or weekly.":                                               "दैनिक या साप्ताहिक चुनें।",
+	"This subscription link has expired or was already used to unsubscribe": "इस सदस्यता लिंक की अवधि समाप्त हो गई है या इसका इस्तेमाल सदस्यता छोड़ने के लिए हो चुका है",
+	"This sign-in link has expired or was already used.":                    "यह साइन-इन लिंक समाप्त हो गया है या पहले ही इस्तेमाल हो चुका है।",
+	"Signing in didn't work. Please try again.":                             "साइन इन नहीं हो सका। कृपया फिर से कोशिश करें।",
+	"The sign-in provider is unavailable right now":                         "साइन-इन प्रदाता अभी उपलब्ध नहीं है",
+	"Enter a 5-digit ZIP code.":                                             "5 अंकों का ZIP कोड डालें।",
+	"We don't know where ZIP code %s is yet.":                               "हमें अभी नहीं पता कि ZIP कोड %s कहाँ है।",
+	"Enter a ZIP code to sort by distance.":                                 "दूरी के हिसाब से क्रम में लगाने के लिए ZIP कोड डालें।",
+}
@@ -0,0 +1,107 @@
+// Package i18n translates the site's UI strings and negotiates the language
+// of each request.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Supported are the languages the UI is translated into, the default first
+var Supported = []language.Tag{language.English, language.Spanish, language.Hindi}
+
+// Names are the languages of Supported in their own language, for the
+// language switcher
+var Names = map[language.Tag]string{
+	language.English: "English",
+	language.Spanish: "Español",
+	language.Hindi:   "हिन्दी",
+}
+
+// catalogs map English messages to their translations. Messages missing from
+// a catalog are shown in English.
+var catalogs = map[language.Tag]map[string]string{
+	language.Spanish: es,
+	language.Hindi:   hi,
+}
+
+var matcher = language.NewMatcher(Supported)
+
+// CookieName is the cookie remembering a language picked with the switcher
+const CookieName = "lang"
+
+type contextKey struct{}
+
+// WithLanguage returns a copy of ctx carrying lang
+func WithLanguage(ctx context.Context, lang language.Tag) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// Language returns the language of ctx, English when none was negotiated
+func Language(ctx context.Context) language.Tag {
+	if lang, ok := ctx.Value(contextKey{}).(language.Tag); ok {
+		return lang
+	}
+	return Supported[0]
+}
+
+// Match returns the supported language best matching the given preferences,
+// e.g. from a lang query parameter, cookie or Accept-Language header
+func Match(preferences ...string) language.Tag {
+	var tags []language.Tag
+	for _, preference := range preferences {
+		if preference == "" {
+			continue
+		}
+		parsed, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, parsed...)
+	}
+	_, index, _ := matcher.Match(tags...)
+	return Supported[index]
+}
+
+// Middleware negotiates the language of each request from the lang query
+// parameter, the lang cookie and Accept-Language, in that order. A lang query
+// parameter also sets the cookie so the choice sticks.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var cookie string
+		if c, err := r.Cookie(CookieName); err == nil {
+			cookie = c.Value
+		}
+		query := r.URL.Query().Get("lang")
+		lang := Match(query, cookie, r.Header.Get("Accept-Language"))
+
+		if query != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     CookieName,
+				Value:    lang.String(),
+				Path:     "/",
+				MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		w.Header().Add("Vary", "Accept-Language, Cookie")
+
+		next.ServeHTTP(w, r.WithContext(WithLanguage(r.Context(), lang)))
+	})
+}
+
+// T translates an English message into the language of ctx. With args the
+// message is a format string for fmt.Sprintf.
+func T(ctx context.Context, message string, args ...any) string {
+	if translated, ok := catalogs[Language(ctx)][message]; ok {
+		message = translated
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
@@ -0,0 +1,181 @@
+// Package mail sends email through a pluggable Mailer. The default is an SMTP
+// server configured from the environment.
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	Headers map[string]string // extra headers, such as List-Unsubscribe
+}
+
+// Mailer sends email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPMailer sends email through an SMTP server, upgrading the connection
+// with STARTTLS when the server offers it
+type SMTPMailer struct {
+	Addr     string // host:port
+	Username string // empty to send without authenticating
+	Password string
+	From     string
+}
+
+// sendTimeout caps how long delivering one message may take, so a stalled
+// SMTP server can't hold up the digests behind it
+const sendTimeout = 30 * time.Second
+
+// Send delivers msg through the SMTP server. The connection is dialed with
+// ctx and given up when ctx is done or after sendTimeout.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	host, _, err := net.SplitHostPort(m.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", m.Addr, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.Addr)
+	if err != nil {
+		return fmt.Errorf("connecting to SMTP server: %w", err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("connecting to SMTP server: %w", err)
+	}
+	// Cancelling ctx interrupts whatever the connection is waiting on
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	if err := m.deliver(conn, host, msg); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("sending mail to %s: %w", msg.To, ctx.Err())
+		}
+		return fmt.Errorf("sending mail to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// deliver runs the SMTP conversation sending msg over conn
+func (m *SMTPMailer) deliver(conn net.Conn, host string, msg Message) error {
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(address(m.From)); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(compose(m.From, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// LogMailer logs messages instead of sending them, for running without an
+// SMTP server
+type LogMailer struct{}
+
+// Send logs msg
+func (LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FromEnv returns an SMTPMailer configured by SMTP_ADDR, SMTP_USER,
+// SMTP_PASSWORD and MAIL_FROM, or a LogMailer when SMTP_ADDR is unset
+func FromEnv() Mailer {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		log.Printf("Mail: SMTP_ADDR is not set, logging mail instead of sending it")
+		return LogMailer{}
+	}
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "mili.fit <noreply@mili.fit>"
+	}
+	return &SMTPMailer{
+		Addr:     addr,
+		Username: os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
+
+// compose formats msg as an RFC 5322 message from the given sender
+func compose(from string, msg Message) []byte {
+	headers := map[string]string{
+		"From":                      from,
+		"To":                        msg.To,
+		"Subject":                   mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":                      time.Now().Format(time.RFC1123Z),
+		"MIME-Version":              "1.0",
+		"Content-Type":              "text/plain; charset=utf-8",
+		"Content-Transfer-Encoding": "8bit",
+	}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		// Header values must not smuggle in extra headers
+		value := strings.NewReplacer("\r", "", "\n", "").Replace(headers[key])
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// address returns the bare address of a sender such as "Name <a@b.c>"
+func address(from string) string {
+	if start := strings.LastIndex(from, "<"); start >= 0 {
+		return strings.TrimSuffix(from[start+1:], ">")
+	}
+	return from
+}
@@ -0,0 +1,172 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// received is what the test SMTP server was sent
+type received struct {
+	from string
+	to   []string
+	data string // raw DATA lines, with their line endings
+}
+
+// serveSMTP accepts one connection on ln and speaks just enough SMTP to take
+// a message, without offering STARTTLS or AUTH
+func serveSMTP(t *testing.T, ln net.Listener) <-chan received {
+	t.Helper()
+	done := make(chan received, 1)
+	go func() {
+		defer close(done)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		var msg received
+		reply("220 test ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); verb {
+			case "EHLO":
+				reply("250-test")
+				reply("250 8BITMIME")
+			case "HELO", "RSET", "NOOP":
+				reply("250 OK")
+			case "MAIL":
+				msg.from = path(cmd)
+				reply("250 OK")
+			case "RCPT":
+				msg.to = append(msg.to, path(cmd))
+				reply("250 OK")
+			case "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				msg.data = data.String()
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				done <- msg
+				return
+			default:
+				reply("502 Unknown command")
+			}
+		}
+	}()
+	return done
+}
+
+// path returns the address between angle brackets in a MAIL or RCPT command
+func path(cmd string) string {
+	_, rest, _ := strings.Cut(cmd, "<")
+	addr, _, _ := strings.Cut(rest, ">")
+	return addr
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := serveSMTP(t, ln)
+
+	mailer := &SMTPMailer{Addr: ln.Addr().String(), From: "mili.fit <noreply@mili.fit>"}
+	err = mailer.Send(context.Background(), Message{
+		To:      "visitor@example.com",
+		Subject: "Your digest: café",
+		Body:    "First line\nSecond line\r\n.leading dot\n",
+		Headers: map[string]string{
+			"List-Unsubscribe": "<https://mili.fit/unsubscribe>\r\nBcc: someone@example.com",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	msg := <-done
+
+	if msg.from != "noreply@mili.fit" {
+		t.Errorf("MAIL FROM = %q, want noreply@mili.fit", msg.from)
+	}
+	if len(msg.to) != 1 || msg.to[0] != "visitor@example.com" {
+		t.Errorf("RCPT TO = %q, want [visitor@example.com]", msg.to)
+	}
+
+	header, body, ok := strings.Cut(msg.data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("no blank line between headers and body in %q", msg.data)
+	}
+	for _, want := range []string{
+		"From: mili.fit <noreply@mili.fit>",
+		"To: visitor@example.com",
+		"Subject: =?utf-8?q?Your_digest:_caf=C3=A9?=",
+		"Content-Type: text/plain; charset=utf-8",
+		"List-Unsubscribe: <https://mili.fit/unsubscribe>Bcc: someone@example.com",
+	} {
+		if !strings.Contains("\r\n"+header+"\r\n", "\r\n"+want+"\r\n") {
+			t.Errorf("headers missing %q:\n%s", want, header)
+		}
+	}
+	if strings.Contains(header, "\r\nBcc:") {
+		t.Errorf("header value smuggled in a Bcc header:\n%s", header)
+	}
+	// The client dot-stuffs lines starting with a dot
+	if want := "First line\r\nSecond line\r\n..leading dot\r\n"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+	if strings.Contains(strings.ReplaceAll(msg.data, "\r\n", ""), "\n") {
+		t.Errorf("message has a bare LF: %q", msg.data)
+	}
+}
+
+func TestSMTPMailerSendGivesUp(t *testing.T) {
+	// A server that accepts the connection but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	mailer := &SMTPMailer{Addr: ln.Addr().String(), From: "noreply@mili.fit"}
+	err = mailer.Send(ctx, Message{To: "visitor@example.com", Subject: "Hi", Body: "Hi"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send took %v after its context ended", elapsed)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,9 +10,22 @@ import (
 	"time"
 
 	"github.com/jritsema/gotoolbox"
+
+	"disaster/auth"
+	"disaster/components"
+	"disaster/digest"
+	"disaster/handlers"
+	"disaster/i18n"
+	"disaster/mail"
+	"disaster/snapshot"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		runExport(os.Args[2:])
+		return
+	}
+
 	//exit process immediately upon sigterm
 	handleSigTerms()
 
@@ -20,7 +34,18 @@ func main() {
 		return fmt.Sprintf("%d", time.Now().UnixNano())
 	}
 
-	middleware := tracing(nextRequestID)(logging(logger)(router))
+	// optional OIDC sign-in for the admin pages
+	handlers.OIDC = auth.OIDCFromEnv()
+
+	// email digests to subscribers in the background
+	handlers.Mailer = mail.FromEnv()
+	_, components.Subscriptions = digest.BaseURL()
+	go digest.Run(context.Background(), handlers.Mailer)
+
+	// mark rows that haven't changed in a long time as stale
+	go snapshot.RunStaleJob(context.Background())
+
+	middleware := tracing(nextRequestID)(logging(logger)(i18n.Middleware(recovery(logger)(router))))
 
 	port := gotoolbox.GetEnvWithDefault("PORT", "8080")
 	logger.Println("listening on http://localhost:" + port)
@@ -2,13 +2,13 @@ package model
 
 // Category represents a resource category
 type Category struct {
-	Name string
+	Name string `json:"name"`
 }
 
 // Resource represents a disaster resource
 type Resource struct {
-	Name        string
-	Description string
-	Category    string
-	Link        string
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Link        string `json:"link"`
 }
@@ -0,0 +1,171 @@
+// Package moderation keeps the queue of resources suggested by visitors until
+// a moderator approves or rejects them.
+package moderation
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"disaster/apperr"
+	"disaster/store"
+)
+
+// submissionsFile holds every submission, reviewed or not
+const submissionsFile = "submissions.json"
+
+// Status is where a submission is in review
+type Status string
+
+const (
+	Pending Status = "pending"
+	// Approving is a submission claimed by a moderator while it is added to
+	// its sheet, so it can't be approved twice
+	Approving Status = "approving"
+	Approved  Status = "approved"
+	Rejected  Status = "rejected"
+)
+
+// Submission is a row suggested for a configured tab
+type Submission struct {
+	ID          string            `json:"id"`
+	SheetID     string            `json:"sheetId"`
+	TabName     string            `json:"tabName"`
+	Category    string            `json:"category"`
+	Values      map[string]string `json:"values"`          // cell text by column header
+	Links       map[string]string `json:"links,omitempty"` // cell hyperlinks by column header
+	SubmittedAt time.Time         `json:"submittedAt"`
+	Status      Status            `json:"status"`
+	ReviewedAt  time.Time         `json:"reviewedAt,omitzero"`
+}
+
+var (
+	mu          sync.Mutex
+	submissions map[string]*Submission
+)
+
+// load reads the submissions from the store once. It must be called with mu
+// held.
+func load() {
+	if submissions != nil {
+		return
+	}
+	submissions = make(map[string]*Submission)
+	if err := store.Load(submissionsFile, &submissions); err != nil {
+		log.Printf("Moderation: error loading submissions: %v", err)
+	}
+}
+
+// Submit adds a submission to the queue as pending and returns it with its ID
+func Submit(sub Submission) (Submission, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Submission{}, fmt.Errorf("generating submission ID: %w", err)
+	}
+	sub.ID = hex.EncodeToString(id)
+	sub.SubmittedAt = time.Now()
+	sub.Status = Pending
+
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	submissions[sub.ID] = &sub
+	if err := store.Save(submissionsFile, submissions); err != nil {
+		delete(submissions, sub.ID)
+		return Submission{}, err
+	}
+	return sub, nil
+}
+
+// Submissions returns the submissions with the given status, oldest first
+func Submissions(status Status) []Submission {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	var list []Submission
+	for _, sub := range submissions {
+		if sub.Status == status {
+			list = append(list, *sub)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].SubmittedAt.Before(list[j].SubmittedAt) })
+	return list
+}
+
+// Get returns a submission by ID
+func Get(id string) (Submission, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	sub, ok := submissions[id]
+	if !ok {
+		return Submission{}, false
+	}
+	return *sub, true
+}
+
+// Claim moves a pending submission to Approving and returns it, so only one
+// moderator adds it to its sheet. Approving it then takes Review, and
+// Release puts it back in the queue when it couldn't be added.
+func Claim(id string) (Submission, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	sub, ok := submissions[id]
+	if !ok || sub.Status != Pending {
+		return Submission{}, apperr.New(apperr.NotFound, "This submission is no longer waiting for review")
+	}
+	sub.Status = Approving
+	if err := store.Save(submissionsFile, submissions); err != nil {
+		sub.Status = Pending
+		return Submission{}, err
+	}
+	return *sub, nil
+}
+
+// Release puts a claimed submission back in the queue
+func Release(id string) {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	sub, ok := submissions[id]
+	if !ok || sub.Status != Approving {
+		return
+	}
+	sub.Status = Pending
+	if err := store.Save(submissionsFile, submissions); err != nil {
+		log.Printf("Moderation: error releasing submission %s: %v", id, err)
+	}
+}
+
+// Review records the moderator's decision on a submission: rejecting a
+// pending one, or approving one claimed with Claim. A claimed submission that
+// fails to save stays claimed, so it isn't added to its sheet again.
+func Review(id string, status Status) error {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	sub, ok := submissions[id]
+	reviewable := ok && (sub.Status == Pending && status == Rejected || sub.Status == Approving && status == Approved)
+	if !reviewable {
+		return apperr.New(apperr.NotFound, "This submission is no longer waiting for review")
+	}
+	previous := *sub
+	sub.Status = status
+	sub.ReviewedAt = time.Now()
+	if err := store.Save(submissionsFile, submissions); err != nil {
+		*sub = previous
+		return err
+	}
+	return nil
+}
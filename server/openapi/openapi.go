@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lowercase HTTP methods to the operations of a path
+type PathItem map[string]*Operation
+
+// Operation is a single API endpoint
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Response is a possible response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the body of a response in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the named schemas referenced by operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON Schema
+type Schema struct {
+	Ref                  string      `json:"$ref,omitempty"`
+	Type                 string      `json:"type,omitempty"`
+	Format               string      `json:"format,omitempty"`
+	Title                string      `json:"title,omitempty"`
+	Description          string      `json:"description,omitempty"`
+	Enum                 []string    `json:"enum,omitempty"`
+	Properties           *Properties `json:"properties,omitempty"`
+	Required             []string    `json:"required,omitempty"`
+	Items                *Schema     `json:"items,omitempty"`
+	AdditionalProperties *Schema     `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema   `json:"oneOf,omitempty"`
+	// SheetColumn is the header of the sheet column a property is read from
+	SheetColumn string `json:"x-sheet-column,omitempty"`
+}
+
+// Property is a named property of an object schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties are the properties of an object schema, kept in struct order
+type Properties []Property
+
+// Get returns the schema of a property by name
+func (p Properties) Get(name string) (*Schema, bool) {
+	for _, prop := range p {
+		if prop.Name == name {
+			return prop.Schema, true
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON writes the properties as a JSON object in order
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(prop.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(prop.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads a JSON object of properties, keeping their order
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	*p = nil
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var schema Schema
+		if err := dec.Decode(&schema); err != nil {
+			return err
+		}
+		*p = append(*p, Property{Name: token.(string), Schema: &schema})
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// Ref returns a schema referencing a named component schema
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// RefName returns the component schema name a reference points at
+func RefName(ref string) string {
+	const prefix = "#/components/schemas/"
+	if len(ref) > len(prefix) && ref[:len(prefix)] == prefix {
+		return ref[len(prefix):]
+	}
+	return ref
+}
@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Generator derives JSON Schemas from Go types. Named struct types become
+// component schemas that are referenced with $ref.
+type Generator struct {
+	Schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewGenerator returns a generator with no component schemas
+func NewGenerator() *Generator {
+	return &Generator{
+		Schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// Define adds a struct type as a component schema under name and returns a
+// reference to it. Types that are not defined explicitly use their Go name.
+func (g *Generator) Define(name string, t reflect.Type) *Schema {
+	if existing, ok := g.names[t]; ok {
+		return Ref(existing)
+	}
+	g.names[t] = name
+	// Reserve the name before recursing so self references terminate
+	g.Schemas[name] = &Schema{}
+	*g.Schemas[name] = *g.structSchema(t)
+	return Ref(name)
+}
+
+// SchemaFor returns the schema of a Go type
+func (g *Generator) SchemaFor(t reflect.Type) *Schema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.SchemaFor(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.SchemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.SchemaFor(t.Elem())}
+	case reflect.Struct:
+		if name, ok := g.names[t]; ok {
+			return Ref(name)
+		}
+		if t.Name() != "" {
+			return g.Define(t.Name(), t)
+		}
+		return g.structSchema(t)
+	default:
+		// Interfaces can hold anything
+		return &Schema{}
+	}
+}
+
+// structSchema returns the object schema of a struct from its json tags.
+// Fields of embedded structs are inlined, fields tagged omitempty or omitzero
+// are optional and col tags are recorded as the sheet column.
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: &Properties{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := g.structSchema(field.Type)
+			*schema.Properties = append(*schema.Properties, *embedded.Properties...)
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.SchemaFor(field.Type)
+		if col, ok := field.Tag.Lookup("col"); ok {
+			prop.SheetColumn = col
+			prop.Description = `Sheet column "` + col + `"`
+		}
+		*schema.Properties = append(*schema.Properties, Property{Name: name, Schema: prop})
+
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,153 @@
+// Package plans keeps saved lists of cards that visitors chose to share. A
+// plan is stored under an unguessable ID, which is all it takes to read it,
+// until it expires after Lifetime.
+package plans
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"disaster/apperr"
+	"disaster/store"
+)
+
+// plansFile holds every shared plan by ID
+const plansFile = "plans.json"
+
+// Lifetime is how long a shared plan can be read
+const Lifetime = 90 * 24 * time.Hour
+
+// maxPlans caps the number of plans kept; sharing another drops the oldest
+const maxPlans = 10000
+
+// MaxItems caps the number of cards in a plan
+const MaxItems = 200
+
+// maxTextLength caps the length of each field of an item
+const maxTextLength = 500
+
+// Item is a saved card, identified by its row ID. The title is kept so a
+// card can still be named once its row is gone, and found again after an
+// edit changes its row ID.
+type Item struct {
+	Category string `json:"category"`
+	SheetID  string `json:"sheetId"`
+	TabName  string `json:"tabName"`
+	RowID    string `json:"rowId"`
+	Title    string `json:"title"`
+}
+
+// Plan is a shared list of saved cards
+type Plan struct {
+	ID        string    `json:"id"`
+	Items     []Item    `json:"items"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+var (
+	mu    sync.Mutex
+	plans map[string]*Plan
+)
+
+// load reads the plans from the store once. It must be called with mu held.
+func load() {
+	if plans != nil {
+		return
+	}
+	plans = make(map[string]*Plan)
+	if err := store.Load(plansFile, &plans); err != nil {
+		log.Printf("Plans: error loading plans: %v", err)
+	}
+}
+
+// ParseItems decodes a list of saved cards as sent by the browser, dropping
+// repeats. Malformed or oversized lists are apperr.BadInput errors.
+func ParseItems(data string) ([]Item, error) {
+	var items []Item
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		return nil, apperr.Wrap(apperr.BadInput, err, "The saved list couldn't be read.")
+	}
+	if len(items) > MaxItems {
+		return nil, apperr.New(apperr.BadInput, "Lists can have at most %d cards.", MaxItems)
+	}
+
+	seen := make(map[string]bool)
+	var parsed []Item
+	for _, item := range items {
+		if item.SheetID == "" || item.TabName == "" || item.RowID == "" {
+			return nil, apperr.New(apperr.BadInput, "The saved list couldn't be read.")
+		}
+		for _, text := range []string{item.Category, item.SheetID, item.TabName, item.RowID, item.Title} {
+			if len(text) > maxTextLength {
+				return nil, apperr.New(apperr.BadInput, "The saved list couldn't be read.")
+			}
+		}
+		if seen[item.RowID] {
+			continue
+		}
+		seen[item.RowID] = true
+		parsed = append(parsed, item)
+	}
+	return parsed, nil
+}
+
+// Create stores a plan of the given items under a new unguessable ID
+func Create(items []Item) (Plan, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Plan{}, fmt.Errorf("generating plan ID: %w", err)
+	}
+	plan := Plan{ID: base64.RawURLEncoding.EncodeToString(id), Items: items, CreatedAt: time.Now()}
+
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	previous := plans
+	plans = kept(plans, plan.CreatedAt)
+	plans[plan.ID] = &plan
+	if err := store.Save(plansFile, plans); err != nil {
+		plans = previous
+		return Plan{}, err
+	}
+	return plan, nil
+}
+
+// kept returns the plans still readable at now, without the oldest ones
+// beyond maxPlans-1 so there is room for one more
+func kept(all map[string]*Plan, now time.Time) map[string]*Plan {
+	var live []*Plan
+	for _, plan := range all {
+		if now.Sub(plan.CreatedAt) < Lifetime {
+			live = append(live, plan)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].CreatedAt.After(live[j].CreatedAt) })
+	if len(live) >= maxPlans {
+		live = live[:maxPlans-1]
+	}
+	result := make(map[string]*Plan, len(live)+1)
+	for _, plan := range live {
+		result[plan.ID] = plan
+	}
+	return result
+}
+
+// Get returns a plan by ID, unless it has expired
+func Get(id string) (Plan, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	plan, ok := plans[id]
+	if !ok || time.Since(plan.CreatedAt) >= Lifetime {
+		return Plan{}, false
+	}
+	return *plan, true
+}
@@ -0,0 +1,60 @@
+// Package ratelimit limits how often clients can do something, such as post
+// a form, within a sliding time window.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepSize is the number of tracked keys above which expired keys are
+// dropped, bounding memory use
+const sweepSize = 10000
+
+// Limiter allows up to Limit events per key in any Window. It is kept in
+// memory, so limits reset when the server restarts.
+type Limiter struct {
+	Limit  int
+	Window time.Duration
+
+	mu   sync.Mutex
+	hits map[string][]time.Time
+}
+
+// New returns a limiter allowing limit events per key in any window
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{Limit: limit, Window: window, hits: make(map[string][]time.Time)}
+}
+
+// Allow records an event for key and reports whether it is within the limit.
+// Events over the limit are not recorded.
+func (l *Limiter) Allow(key string) bool {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.hits) > sweepSize {
+		for k, times := range l.hits {
+			if len(l.recent(times, now)) == 0 {
+				delete(l.hits, k)
+			}
+		}
+	}
+
+	times := l.recent(l.hits[key], now)
+	if len(times) >= l.Limit {
+		l.hits[key] = times
+		return false
+	}
+	l.hits[key] = append(times, now)
+	return true
+}
+
+// recent returns the times within the window before now
+func (l *Limiter) recent(times []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-l.Window)
+	for len(times) > 0 && !times[0].After(cutoff) {
+		times = times[1:]
+	}
+	return times
+}
@@ -0,0 +1,209 @@
+// Package reports keeps visitors' reports of cards that are outdated or wrong,
+// so moderators can see which rows need checking and visitors can be warned.
+package reports
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"disaster/auth"
+	"disaster/store"
+)
+
+// reportsFile holds every open report by row ID
+const reportsFile = "reports.json"
+
+// defaultThreshold is the number of reports after which a row is flagged when
+// REPORT_THRESHOLD is unset
+const defaultThreshold = 3
+
+// Reason is why a card was reported
+type Reason string
+
+const (
+	Outdated  Reason = "outdated"
+	Incorrect Reason = "incorrect"
+	Closed    Reason = "closed"
+	Other     Reason = "other"
+)
+
+// Reasons are the reasons a visitor can pick from, in the order shown
+var Reasons = []Reason{Outdated, Incorrect, Closed, Other}
+
+// Label returns the English label of a reason, for i18n.T
+func (r Reason) Label() string {
+	switch r {
+	case Outdated:
+		return "Outdated, such as an expired code"
+	case Incorrect:
+		return "Incorrect details"
+	case Closed:
+		return "No longer available"
+	default:
+		return "Something else"
+	}
+}
+
+// Valid reports whether r is one of Reasons
+func (r Reason) Valid() bool {
+	for _, reason := range Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Report is a visitor's report of a problem with a row
+type Report struct {
+	RowID      string    `json:"rowId"`
+	SheetID    string    `json:"sheetId"`
+	TabName    string    `json:"tabName"`
+	Category   string    `json:"category"`
+	Title      string    `json:"title"` // the row's company, to tell rows apart
+	Reason     Reason    `json:"reason"`
+	Comment    string    `json:"comment,omitempty"`
+	Reporter   string    `json:"reporter"` // hash of the reporter's address
+	ReportedAt time.Time `json:"reportedAt"`
+}
+
+// RowReports is the open reports of one row
+type RowReports struct {
+	RowID    string
+	SheetID  string
+	TabName  string
+	Category string
+	Title    string
+	Reports  []Report // newest first
+}
+
+// Count returns the number of open reports of the row
+func (r RowReports) Count() int {
+	return len(r.Reports)
+}
+
+// Reasons returns how often each reason was given for the row
+func (r RowReports) Reasons() map[Reason]int {
+	counts := make(map[Reason]int)
+	for _, report := range r.Reports {
+		counts[report.Reason]++
+	}
+	return counts
+}
+
+var (
+	mu      sync.Mutex
+	reports map[string][]Report
+)
+
+// load reads the reports from the store once. It must be called with mu held.
+func load() {
+	if reports != nil {
+		return
+	}
+	reports = make(map[string][]Report)
+	if err := store.Load(reportsFile, &reports); err != nil {
+		log.Printf("Reports: error loading reports: %v", err)
+	}
+}
+
+// Threshold returns the number of reports, from REPORT_THRESHOLD, after which
+// a row is shown as possibly outdated
+func Threshold() int {
+	if n, err := strconv.Atoi(os.Getenv("REPORT_THRESHOLD")); err == nil && n > 0 {
+		return n
+	}
+	return defaultThreshold
+}
+
+// ReporterKey identifies a reporter by a keyed hash of their address, so
+// repeated reports from one visitor count once without storing the address.
+// addr must be the address the server trusts, not one the client reported.
+func ReporterKey(addr string) string {
+	return auth.AddressKey("reports", addr)
+}
+
+// Add records a report. A later report of the same row by the same reporter
+// replaces their earlier one.
+func Add(report Report) error {
+	report.ReportedAt = time.Now()
+
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	previous := reports[report.RowID]
+	updated := []Report{report}
+	for _, r := range previous {
+		if r.Reporter != report.Reporter {
+			updated = append(updated, r)
+		}
+	}
+	reports[report.RowID] = updated
+	if err := store.Save(reportsFile, reports); err != nil {
+		reports[report.RowID] = previous
+		return err
+	}
+	return nil
+}
+
+// Flagged reports whether a row has reached the report threshold
+func Flagged(rowID string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	return len(reports[rowID]) >= Threshold()
+}
+
+// Ranked returns the rows with open reports, most reported first
+func Ranked() []RowReports {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	ranked := make([]RowReports, 0, len(reports))
+	for rowID, list := range reports {
+		if len(list) == 0 {
+			continue
+		}
+		latest := list[0]
+		ranked = append(ranked, RowReports{
+			RowID:    rowID,
+			SheetID:  latest.SheetID,
+			TabName:  latest.TabName,
+			Category: latest.Category,
+			Title:    latest.Title,
+			Reports:  append([]Report(nil), list...),
+		})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count() != ranked[j].Count() {
+			return ranked[i].Count() > ranked[j].Count()
+		}
+		return ranked[i].Reports[0].ReportedAt.After(ranked[j].Reports[0].ReportedAt)
+	})
+	return ranked
+}
+
+// Resolve closes the reports of a row once it has been checked
+func Resolve(rowID string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	previous, ok := reports[rowID]
+	if !ok {
+		return nil
+	}
+	delete(reports, rowID)
+	if err := store.Save(reportsFile, reports); err != nil {
+		reports[rowID] = previous
+		return err
+	}
+	return nil
+}
@@ -3,15 +3,80 @@ package main
 import (
 	"net/http"
 
+	"disaster/auth"
 	"disaster/handlers"
 )
 
 func setupRoutes(router *http.ServeMux) {
 	router.Handle("GET /", http.HandlerFunc(handlers.Index))
-	router.Handle("POST /resources", http.HandlerFunc(handlers.HandleResourcesByCategory))
+	router.Handle("GET /search", http.HandlerFunc(handlers.HandleSearch))
 	router.Handle("GET /api/sheet-tabs/", http.HandlerFunc(handlers.HandleSheetTabs))
-	router.Handle("GET /api/sheet-data/", http.HandlerFunc(handlers.HandleSheetData))
-	router.Handle("POST /api/render/sheet-tabs", http.HandlerFunc(handlers.HandleRenderSheetTabs))
+
+	// Pages for browsing the directory, enhanced with hx-boost
+	router.Handle("GET /c/{category}", http.HandlerFunc(handlers.HandleCategoryPage))
+	router.Handle("GET /c/{category}/s/{sheet}", http.HandlerFunc(handlers.HandleSheetPage))
+	router.Handle("GET /c/{category}/s/{sheet}/t/{tab}", http.HandlerFunc(handlers.HandleTabPage))
+	router.Handle("GET /c/{category}/s/{sheet}/t/{tab}/r/{row}", http.HandlerFunc(handlers.HandleRowPage))
+
+	// Sign-in for the people who run the site, by emailed link or OIDC
+	router.Handle("GET /login", http.HandlerFunc(handlers.HandleLoginPage))
+	router.Handle("POST /login", http.HandlerFunc(handlers.HandleLogin))
+	router.Handle("GET /login/link", http.HandlerFunc(handlers.HandleLoginLinkPage))
+	router.Handle("POST /login/link", http.HandlerFunc(handlers.HandleLoginLink))
+	router.Handle("GET /login/oidc", http.HandlerFunc(handlers.HandleOIDCLogin))
+	router.Handle("GET /login/oidc/callback", http.HandlerFunc(handlers.HandleOIDCCallback))
+	router.Handle("POST /logout", http.HandlerFunc(handlers.HandleLogout))
+
+	// Dashboard of the running site
+	router.Handle("GET /admin", handlers.RequireRole(auth.Viewer, http.HandlerFunc(handlers.HandleAdminDashboard)))
+	router.Handle("POST /admin/refresh", handlers.RequireRole(auth.Admin, http.HandlerFunc(handlers.HandleAdminRefresh)))
+
+	// Suggestions from visitors, added to the sheets once a moderator approves them
+	router.Handle("GET /c/{category}/s/{sheet}/t/{tab}/suggest", http.HandlerFunc(handlers.HandleSuggestPage))
+	router.Handle("POST /c/{category}/s/{sheet}/t/{tab}/suggest", http.HandlerFunc(handlers.HandleSuggest))
+	router.Handle("GET /admin/moderation", handlers.RequireRole(auth.Viewer, http.HandlerFunc(handlers.HandleModeration)))
+	router.Handle("POST /admin/moderation/{id}/approve", handlers.RequireRole(auth.Editor, http.HandlerFunc(handlers.HandleApproveSubmission)))
+	router.Handle("POST /admin/moderation/{id}/reject", handlers.RequireRole(auth.Editor, http.HandlerFunc(handlers.HandleRejectSubmission)))
+
+	// Reports of outdated or wrong cards, and votes on whether they worked
+	router.Handle("POST /c/{category}/s/{sheet}/t/{tab}/r/{row}/report", http.HandlerFunc(handlers.HandleReport))
+	router.Handle("POST /c/{category}/s/{sheet}/t/{tab}/r/{row}/confirm", http.HandlerFunc(handlers.HandleConfirm))
+	router.Handle("GET /admin/reports", handlers.RequireRole(auth.Viewer, http.HandlerFunc(handlers.HandleReportedRows)))
+	router.Handle("POST /admin/reports/{row}/resolve", handlers.RequireRole(auth.Editor, http.HandlerFunc(handlers.HandleResolveReports)))
+
+	// Cards that look like the same company, merged for visitors when MERGE_DUPLICATES is set
+	router.Handle("GET /admin/duplicates", handlers.RequireRole(auth.Viewer, http.HandlerFunc(handlers.HandleDuplicates)))
+	router.Handle("GET /companies/{key}", http.HandlerFunc(handlers.HandleCompanyPage))
+
+	// Cards saved to a plan on the visitor's device, and plans shared by link
+	router.Handle("GET /plan", http.HandlerFunc(handlers.HandlePlanPage))
+	router.Handle("POST /plan", http.HandlerFunc(handlers.HandlePlanEntries))
+	router.Handle("POST /plans", http.HandlerFunc(handlers.HandleSharePlan))
+	router.Handle("GET /plans/{id}", http.HandlerFunc(handlers.HandleSharedPlan))
+
+	// Evacuation and other impact zones covering an address
+	router.Handle("GET /zones", http.HandlerFunc(handlers.HandleZonesPage))
+
+	// Email digests of new and changed listings
+	router.Handle("POST /subscribe", http.HandlerFunc(handlers.HandleSubscribe))
+	router.Handle("GET /subscriptions/confirm", http.HandlerFunc(handlers.HandleConfirmSubscription))
+	router.Handle("GET /subscriptions/unsubscribe", http.HandlerFunc(handlers.HandleUnsubscribePage))
+	router.Handle("POST /subscriptions/unsubscribe", http.HandlerFunc(handlers.HandleUnsubscribe))
+
+	// Search engines
+	router.Handle("GET /sitemap.xml", http.HandlerFunc(handlers.HandleSitemap))
+	router.Handle("GET /robots.txt", http.HandlerFunc(handlers.HandleRobots))
+
+	// Versioned JSON API, described by its OpenAPI document
+	for _, op := range handlers.APIOperations {
+		router.Handle(op.Method+" "+op.Path, op.Handler)
+	}
+	router.Handle("GET /api/v1/openapi.json", http.HandlerFunc(handlers.HandleOpenAPI))
+
+	// Feeds of newly added rows, ?format=rss|atom|json
+	router.Handle("GET /feeds/new", http.HandlerFunc(handlers.HandleWhatsNewFeed))
+	router.Handle("GET /feeds/sheets/{id}/tabs/{tab}", http.HandlerFunc(handlers.HandleTabFeed))
+	router.Handle("GET /feeds/categories/{category}", http.HandlerFunc(handlers.HandleCategoryFeed))
 
 	// Serve static files with cache headers
 	fileServer := http.FileServer(http.Dir("static"))
@@ -0,0 +1,206 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// prefixWeight scales matches where a query term is a prefix of an indexed term
+	prefixWeight = 0.7
+	// fuzzyWeight scales matches within the allowed edit distance of a query term
+	fuzzyWeight = 0.5
+)
+
+// Field is a piece of a document's text with its relevance boost
+type Field struct {
+	Text  string
+	Boost float64
+}
+
+// Document is an item added to the index
+type Document struct {
+	ID     string
+	Group  string // results are grouped by this key
+	Fields []Field
+	Value  any // returned with results, e.g. the resource or parsed row
+}
+
+// Result is a document matching a query
+type Result struct {
+	Document
+	Score float64
+}
+
+// Index is an immutable in-memory inverted index
+type Index struct {
+	docs     []Document
+	postings map[string]map[int]float64 // term to document index to boost
+	terms    []string                   // sorted vocabulary for prefix lookup
+}
+
+// NewIndex builds an index over docs
+func NewIndex(docs []Document) *Index {
+	idx := &Index{
+		docs:     docs,
+		postings: make(map[string]map[int]float64),
+	}
+
+	for i, doc := range docs {
+		for _, field := range doc.Fields {
+			for _, term := range Tokenize(field.Text) {
+				postings, ok := idx.postings[term]
+				if !ok {
+					postings = make(map[int]float64)
+					idx.postings[term] = postings
+					idx.terms = append(idx.terms, term)
+				}
+				// A term scores with the most important field it appears in
+				postings[i] = max(postings[i], field.Boost)
+			}
+		}
+	}
+	sort.Strings(idx.terms)
+
+	return idx
+}
+
+// Len returns the number of documents in the index
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search returns the documents matching every term of query, best first.
+// Each query term matches indexed terms exactly, as a prefix, or within a
+// small edit distance that grows with the term's length.
+func (idx *Index) Search(query string) []Result {
+	queryTerms := Tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	var scores map[int]float64
+	for _, queryTerm := range queryTerms {
+		termScores := idx.match(queryTerm)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		// Keep only documents that matched every previous term
+		for i := range scores {
+			if s, ok := termScores[i]; ok {
+				scores[i] += s
+			} else {
+				delete(scores, i)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for i, score := range scores {
+		results = append(results, Result{Document: idx.docs[i], Score: score})
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].ID < results[b].ID
+	})
+
+	return results
+}
+
+// match scores every document containing a term that matches queryTerm
+func (idx *Index) match(queryTerm string) map[int]float64 {
+	scores := make(map[int]float64)
+	add := func(term string, weight float64) {
+		for i, boost := range idx.postings[term] {
+			scores[i] = max(scores[i], weight*boost)
+		}
+	}
+
+	add(queryTerm, 1)
+
+	// Prefix matches; the vocabulary is sorted so they are contiguous
+	if len([]rune(queryTerm)) >= 2 {
+		start := sort.SearchStrings(idx.terms, queryTerm)
+		for _, term := range idx.terms[start:] {
+			if !strings.HasPrefix(term, queryTerm) {
+				break
+			}
+			if term != queryTerm {
+				add(term, prefixWeight)
+			}
+		}
+	}
+
+	// Typo tolerant matches
+	maxEdits := allowedEdits(queryTerm)
+	if maxEdits > 0 {
+		for _, term := range idx.terms {
+			if term == queryTerm {
+				continue
+			}
+			if d := editDistance(queryTerm, term, maxEdits); d <= maxEdits {
+				add(term, fuzzyWeight/float64(d))
+			}
+		}
+	}
+
+	return scores
+}
+
+// allowedEdits returns how many typos a query term of this length may contain
+func allowedEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Tokenize lowercases text and splits it into runs of letters and digits,
+// keeping combining marks such as Devanagari vowel signs with their letters
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// editDistance returns the Levenshtein distance between a and b, or
+// limit+1 as soon as it is known to exceed limit
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
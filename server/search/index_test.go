@@ -0,0 +1,105 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Food Bank", []string{"food", "bank"}},
+		{"  24/7 hotline!  ", []string{"24", "7", "hotline"}},
+		{"St. Mary's-Church", []string{"st", "mary", "s", "church"}},
+		{"Café ÜBER", []string{"café", "über"}},
+		{"खाद्य बैंक", []string{"खाद्य", "बैंक"}},
+		{"--- ...", nil},
+		{"", nil},
+	}
+	for _, test := range tests {
+		if got := Tokenize(test.text); !slices.Equal(got, test.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := NewIndex([]Document{
+		{ID: "shelter", Fields: []Field{{Text: "Red Cross Shelter", Boost: 3}, {Text: "Beds and meals", Boost: 1}}},
+		{ID: "pantry", Fields: []Field{{Text: "Food Pantry", Boost: 3}, {Text: "Free meals on weekdays", Boost: 1}}},
+		{ID: "kitchen", Fields: []Field{{Text: "Community Kitchen", Boost: 3}, {Text: "Hot meals near the shelter", Boost: 1}}},
+		{ID: "clinic", Fields: []Field{{Text: "Mobile Clinic", Boost: 3}, {Text: "Medical care", Boost: 1}}},
+	})
+	tests := []struct {
+		query string
+		want  []string // IDs, best first
+	}{
+		// A title match outranks a match in the description
+		{"shelter", []string{"shelter", "kitchen"}},
+		// Ties are broken by ID
+		{"meals", []string{"kitchen", "pantry", "shelter"}},
+		// Every term must match
+		{"meals shelter", []string{"shelter", "kitchen"}},
+		{"meals clinic", []string{}},
+		// Prefixes of two or more letters match
+		{"pan", []string{"pantry"}},
+		{"p", []string{}},
+		// Typos are forgiven once words are long enough
+		{"shelfer", []string{"shelter", "kitchen"}},
+		{"clinik", []string{"clinic"}},
+		{"fod", []string{}},
+		// Queries are tokenised like the documents
+		{"FOOD-pantry!", []string{"pantry"}},
+		{"", []string{}},
+	}
+	for _, test := range tests {
+		got := []string{}
+		for _, result := range idx.Search(test.query) {
+			got = append(got, result.ID)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Search(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+}
+
+func TestSearchScores(t *testing.T) {
+	idx := NewIndex([]Document{
+		{ID: "exact", Fields: []Field{{Text: "water", Boost: 1}}},
+		{ID: "prefix", Fields: []Field{{Text: "waterproof", Boost: 1}}},
+		{ID: "typo", Fields: []Field{{Text: "waiter", Boost: 1}}},
+	})
+	scores := map[string]float64{}
+	for _, result := range idx.Search("water") {
+		scores[result.ID] = result.Score
+	}
+	want := map[string]float64{"exact": 1, "prefix": prefixWeight, "typo": fuzzyWeight}
+	for id, score := range want {
+		if scores[id] != score {
+			t.Errorf("Search(%q) scored %s %v, want %v", "water", id, scores[id], score)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"shelter", "shelter", 2, 0},
+		{"shelter", "shelfer", 2, 1},
+		{"shelter", "shelters", 2, 1},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 2, 3},
+		{"food", "foodbank", 2, 3},
+		{"café", "cafe", 1, 1},
+	}
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b, test.limit); got != test.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", test.a, test.b, test.limit, got, test.want)
+		}
+	}
+}
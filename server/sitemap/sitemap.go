@@ -0,0 +1,52 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// MaxURLs is the most URLs a single sitemap may list
+const MaxURLs = 50000
+
+// URL is a page listed in a sitemap
+type URL struct {
+	Loc     string    // absolute URL of the page
+	LastMod time.Time // when the page last changed, zero if unknown
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []url    `xml:"url"`
+}
+
+type url struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Write writes urls as a sitemaps.org XML sitemap, keeping the first MaxURLs
+func Write(w io.Writer, urls []URL) error {
+	if len(urls) > MaxURLs {
+		urls = urls[:MaxURLs]
+	}
+
+	set := urlSet{URLs: make([]url, 0, len(urls))}
+	for _, u := range urls {
+		entry := url{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		set.URLs = append(set.URLs, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,123 @@
+package snapshot
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"disaster/store"
+)
+
+// changesFile is the log of rows added or changed between snapshots
+const changesFile = "changes.json"
+
+// keepChanges is how long changes are kept, long enough for weekly digests
+const keepChanges = 8 * 24 * time.Hour
+
+// ChangeKind is how a row differs from the previous snapshot
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Changed ChangeKind = "changed"
+)
+
+// Change is a row that appeared in a snapshot. Rows are identified by their
+// content, so an edited row shows up as a new row ID; it counts as changed
+// when a row with the same title left the tab in the same snapshot.
+type Change struct {
+	At      time.Time  `json:"at"`
+	Kind    ChangeKind `json:"kind"`
+	SheetID string     `json:"sheetId"`
+	TabName string     `json:"tabName"`
+	RowID   string     `json:"rowId"`
+	Title   string     `json:"title"`
+}
+
+var (
+	changesMu sync.Mutex
+	changes   []Change
+	// changesLoaded records whether changes holds the store's log
+	changesLoaded bool
+)
+
+// loadChanges reads the change log from the store once. It must be called
+// with changesMu held.
+func loadChanges() {
+	if changesLoaded {
+		return
+	}
+	changesLoaded = true
+	if err := store.Load(changesFile, &changes); err != nil {
+		log.Printf("Snapshot: error loading changes: %v", err)
+	}
+}
+
+// recordChanges logs the rows of snap with newly seen IDs, comparing against
+// the previous snapshot, if any, to tell changed rows from added ones
+func recordChanges(previous, snap *Snapshot, newIDs map[string]bool) {
+	var found []Change
+	for _, tab := range snap.Tabs {
+		// Titles of rows that left the tab in this snapshot
+		left := make(map[string]bool)
+		if previous != nil {
+			if old, ok := previous.Tab(tab.SheetID, tab.TabName); ok {
+				kept := make(map[string]bool, len(tab.Rows))
+				for _, row := range tab.Rows {
+					kept[tab.RowID(row)] = true
+				}
+				for _, row := range old.Rows {
+					if !kept[old.RowID(row)] {
+						left[old.Title(row)] = true
+					}
+				}
+			}
+		}
+
+		for _, row := range tab.Rows {
+			id := tab.RowID(row)
+			if !newIDs[id] {
+				continue
+			}
+			change := Change{At: snap.TakenAt, Kind: Added, SheetID: tab.SheetID, TabName: tab.TabName, RowID: id, Title: tab.Title(row)}
+			if left[change.Title] {
+				change.Kind = Changed
+			}
+			found = append(found, change)
+		}
+	}
+	if len(found) == 0 {
+		return
+	}
+
+	changesMu.Lock()
+	defer changesMu.Unlock()
+	loadChanges()
+
+	cutoff := snap.TakenAt.Add(-keepChanges)
+	kept := changes[:0]
+	for _, change := range changes {
+		if change.At.After(cutoff) {
+			kept = append(kept, change)
+		}
+	}
+	changes = append(kept, found...)
+	if err := store.Save(changesFile, changes); err != nil {
+		log.Printf("Snapshot: error saving changes: %v", err)
+	}
+}
+
+// ChangesSince returns the rows added or changed after t, oldest first
+func ChangesSince(t time.Time) []Change {
+	changesMu.Lock()
+	defer changesMu.Unlock()
+	loadChanges()
+
+	var since []Change
+	for _, change := range changes {
+		if change.At.After(t) {
+			since = append(since, change)
+		}
+	}
+	return since
+}
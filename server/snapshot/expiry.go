@@ -0,0 +1,27 @@
+package snapshot
+
+import (
+	"time"
+
+	"disaster/hours"
+)
+
+// ExpiryColumns are the optional columns giving the last day a row's offer
+// is valid, on any card type. The first one a tab has is used.
+var ExpiryColumns = []string{"Valid Until", "Expires"}
+
+// ExpiresAt returns when a row's offer stops being valid: the end of the
+// day in its Valid Until column, in the time zone of hours.Location
+func (t *Tab) ExpiresAt(row any) (time.Time, bool) {
+	validUntil, ok := t.Expires[t.RowID(row)]
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Date(validUntil.Year(), validUntil.Month(), validUntil.Day()+1, 0, 0, 0, 0, hours.Location), true
+}
+
+// Expired reports whether a row's offer is no longer valid at now
+func (t *Tab) Expired(row any, now time.Time) bool {
+	expiresAt, ok := t.ExpiresAt(row)
+	return ok && !now.Before(expiresAt)
+}
@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"disaster/components/sheet_row_cards"
+	"disaster/store"
+)
+
+// firstSeenFile records when each row ID first appeared in a snapshot. Row
+// IDs hash a row's cells, so this is when each version of a row appeared.
+const firstSeenFile = "first_seen.json"
+
+// firstListedFile records when each listing, a row known by its tab and
+// title, first appeared in a snapshot, so editing a row doesn't make it new
+const firstListedFile = "first_listed.json"
+
+var (
+	firstSeenMu sync.Mutex
+	firstSeen   map[string]time.Time
+	firstListed map[string]time.Time
+)
+
+// loadFirstSeen reads the first seen times from the store once. It must be
+// called with firstSeenMu held.
+func loadFirstSeen() {
+	if firstSeen != nil {
+		return
+	}
+	firstSeen = make(map[string]time.Time)
+	if err := store.Load(firstSeenFile, &firstSeen); err != nil {
+		log.Printf("Snapshot: error loading first seen times: %v", err)
+	}
+	firstListed = make(map[string]time.Time)
+	if err := store.Load(firstListedFile, &firstListed); err != nil {
+		log.Printf("Snapshot: error loading first listed times: %v", err)
+	}
+}
+
+// listingKey identifies a row by its tab and title, which survive edits to
+// its other cells. Rows of a tab sharing a title share a key.
+func listingKey(t *Tab, row any) string {
+	return t.SheetID + "\x00" + t.TabName + "\x00" + strings.ToLower(strings.TrimSpace(t.Title(row)))
+}
+
+// recordFirstSeen stamps every row ID and listing of snap that has not been
+// seen before with the time the snapshot was taken and returns the new row
+// IDs. Nothing is returned for the first snapshot ever recorded, whose rows
+// are all new.
+//
+// IDs and listings that are no longer in snap are forgotten, unless part of
+// snap failed to load, so a row that is removed and later listed again counts
+// as new.
+func recordFirstSeen(snap *Snapshot) map[string]bool {
+	firstSeenMu.Lock()
+	defer firstSeenMu.Unlock()
+	loadFirstSeen()
+
+	firstRecord := len(firstSeen) == 0
+	newIDs := make(map[string]bool)
+	ids := make(map[string]bool)
+	listings := make(map[string]bool)
+	listed := false
+	for _, tab := range snap.Tabs {
+		for _, row := range tab.Rows {
+			id, key := tab.RowID(row), listingKey(tab, row)
+			ids[id], listings[key] = true, true
+			if _, ok := firstListed[key]; !ok {
+				// Listings recorded before the row's current version keep
+				// the time that version appeared
+				at, ok := firstSeen[id]
+				if !ok {
+					at = snap.TakenAt
+				}
+				firstListed[key] = at
+				listed = true
+			}
+			if _, ok := firstSeen[id]; !ok {
+				firstSeen[id] = snap.TakenAt
+				newIDs[id] = true
+			}
+		}
+	}
+
+	seenPruned, listedPruned := false, false
+	if len(snap.Errors) == 0 {
+		seenPruned = prune(firstSeen, ids)
+		listedPruned = prune(firstListed, listings)
+	}
+	if len(newIDs) > 0 || seenPruned {
+		if err := store.Save(firstSeenFile, firstSeen); err != nil {
+			log.Printf("Snapshot: error saving first seen times: %v", err)
+		}
+	}
+	if listed || listedPruned {
+		if err := store.Save(firstListedFile, firstListed); err != nil {
+			log.Printf("Snapshot: error saving first listed times: %v", err)
+		}
+	}
+	if firstRecord {
+		return nil
+	}
+	return newIDs
+}
+
+// prune deletes the keys of times that aren't in keep and reports whether
+// there were any
+func prune(times map[string]time.Time, keep map[string]bool) bool {
+	pruned := false
+	for key := range times {
+		if !keep[key] {
+			delete(times, key)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// FirstSeen returns when a row first appeared in a snapshot in its current
+// form
+func FirstSeen(rowID string) (time.Time, bool) {
+	firstSeenMu.Lock()
+	defer firstSeenMu.Unlock()
+	loadFirstSeen()
+
+	t, ok := firstSeen[rowID]
+	return t, ok
+}
+
+// firstListedAt returns when a row's listing first appeared in a snapshot
+func firstListedAt(t *Tab, row any) (time.Time, bool) {
+	firstSeenMu.Lock()
+	defer firstSeenMu.Unlock()
+	loadFirstSeen()
+
+	at, ok := firstListed[listingKey(t, row)]
+	return at, ok
+}
+
+// AddedAt returns when a row was added to the tab: its Date Added column
+// when the row type has one and it is set, otherwise the time a row with its
+// title first appeared in the tab. Edits to a row's other cells don't change
+// it.
+func (t *Tab) AddedAt(row any) time.Time {
+	if field, ok := sheet_row_cards.FieldByCol(t.CardType.RowType, "Date Added"); ok {
+		if added, ok := reflect.ValueOf(row).Field(field.Index).Interface().(time.Time); ok && !added.IsZero() {
+			return added
+		}
+	}
+	listed, _ := firstListedAt(t, row)
+	return listed
+}
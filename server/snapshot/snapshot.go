@@ -0,0 +1,217 @@
+package snapshot
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"disaster/gdrive"
+	"disaster/model"
+)
+
+// MaxAge is how long a snapshot is served before it is refreshed in the background
+var MaxAge = 10 * time.Minute
+
+// Snapshot is the parsed content of the master sheet and every configured tab
+// at a point in time
+type Snapshot struct {
+	TakenAt    time.Time
+	Categories []model.Category
+	Resources  []model.Resource
+	Tabs       []*Tab
+	// Errors maps "<sheet ID>/<tab name>" to the error that kept a tab from
+	// loading, in which case the tab is the previous snapshot's copy, if any;
+	// the master sheet's categories and resources are keyed "categories" and
+	// "resources"
+	Errors map[string]error
+}
+
+// Tab returns a tab of the snapshot by sheet ID and tab name
+func (s *Snapshot) Tab(sheetID, tabName string) (*Tab, bool) {
+	for _, tab := range s.Tabs {
+		if tab.SheetID == sheetID && tab.TabName == tabName {
+			return tab, true
+		}
+	}
+	return nil, false
+}
+
+// Take fetches the master sheet and every tab in gdrive.SheetConfig. A tab
+// that fails to load is recorded in Errors and left out of the snapshot;
+// Refresh fills it in from the previous one.
+func Take(ctx context.Context) *Snapshot {
+	snap := &Snapshot{
+		TakenAt: time.Now(),
+		Errors:  make(map[string]error),
+	}
+
+	categories, err := gdrive.GetCategories(ctx)
+	if err != nil {
+		log.Printf("Snapshot: error fetching categories: %v", err)
+		snap.Errors["categories"] = err
+	}
+	snap.Categories = categories
+
+	resources, err := gdrive.GetResources(ctx)
+	if err != nil {
+		log.Printf("Snapshot: error fetching resources: %v", err)
+		snap.Errors["resources"] = err
+	}
+	snap.Resources = resources
+
+	sheetIDs := make([]string, 0, len(gdrive.SheetConfig))
+	for sheetID := range gdrive.SheetConfig {
+		sheetIDs = append(sheetIDs, sheetID)
+	}
+	sort.Strings(sheetIDs)
+
+	for _, sheetID := range sheetIDs {
+		tabNames := make([]string, 0, len(gdrive.SheetConfig[sheetID]))
+		for tabName := range gdrive.SheetConfig[sheetID] {
+			tabNames = append(tabNames, tabName)
+		}
+		sort.Strings(tabNames)
+
+		for _, tabName := range tabNames {
+			tab, err := LoadTab(ctx, sheetID, tabName)
+			if err != nil {
+				log.Printf("Snapshot: error loading tab %s of sheet %s: %v", tabName, sheetID, err)
+				snap.Errors[sheetID+"/"+tabName] = err
+				continue
+			}
+			snap.Tabs = append(snap.Tabs, tab)
+		}
+	}
+
+	return snap
+}
+
+// firstLoadTimeout caps how long the first snapshot may take, since requests
+// wait for it
+const firstLoadTimeout = 2 * time.Minute
+
+var (
+	mu          sync.Mutex
+	current     *Snapshot
+	refreshing  bool
+	attemptedAt time.Time // when the last refresh was started
+	// firstLoad is closed once the first snapshot is taken; nil until a
+	// request asks for it
+	firstLoad chan struct{}
+)
+
+// Current returns the cached snapshot. The first call takes a snapshot,
+// which every request waits for until it is ready or ctx is done; later calls
+// return the cached one and refresh it in the background once it is older
+// than MaxAge. A request that gives up waiting gets an empty snapshot.
+func Current(ctx context.Context) *Snapshot {
+	mu.Lock()
+	snap := current
+	stale := snap != nil && time.Since(attemptedAt) > MaxAge
+	if stale && !refreshing {
+		refreshing = true
+		attemptedAt = time.Now()
+		go func() {
+			Refresh(context.Background())
+			mu.Lock()
+			refreshing = false
+			mu.Unlock()
+		}()
+	}
+	if snap == nil && firstLoad == nil {
+		// The first snapshot is taken once, for every waiting request, and
+		// outlives any one of them
+		loaded := make(chan struct{})
+		firstLoad = loaded
+		go func() {
+			defer close(loaded)
+			ctx, cancel := context.WithTimeout(context.Background(), firstLoadTimeout)
+			defer cancel()
+			Refresh(ctx)
+		}()
+	}
+	loaded := firstLoad
+	mu.Unlock()
+
+	if snap != nil {
+		return snap
+	}
+	select {
+	case <-loaded:
+	case <-ctx.Done():
+		return &Snapshot{TakenAt: time.Now(), Errors: map[string]error{"snapshot": ctx.Err()}}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// State returns the cached snapshot, nil before the first one is taken, and
+// whether a background refresh is running. Unlike Current it never triggers a
+// refresh.
+func State() (snap *Snapshot, refreshing bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	return current, refreshing
+}
+
+// Refresh takes a new snapshot, records when its rows were first seen and
+// which rows were added or changed, and makes it the current one. Whatever
+// fails to load is kept from the current snapshot, so an outage or quota
+// error at the sheets doesn't empty the site; when nothing loads at all the
+// current snapshot stays as it is. It returns the snapshot now current.
+func Refresh(ctx context.Context) *Snapshot {
+	snap := Take(ctx)
+
+	mu.Lock()
+	attemptedAt = time.Now()
+	previous := current
+	mu.Unlock()
+
+	if previous != nil {
+		if len(snap.Tabs) == 0 && snap.Errors["categories"] != nil && snap.Errors["resources"] != nil {
+			log.Printf("Snapshot: nothing loaded, keeping the snapshot taken at %s", previous.TakenAt.Format(time.RFC3339))
+			return previous
+		}
+		keepFailed(previous, snap)
+	}
+	newIDs := recordFirstSeen(snap)
+
+	mu.Lock()
+	current = snap
+	mu.Unlock()
+
+	recordChanges(previous, snap, newIDs)
+
+	return snap
+}
+
+// keepFailed fills in what failed to load into snap with its copy from
+// previous. The errors stay recorded in snap.Errors.
+func keepFailed(previous, snap *Snapshot) {
+	if snap.Errors["categories"] != nil {
+		snap.Categories = previous.Categories
+	}
+	if snap.Errors["resources"] != nil {
+		snap.Resources = previous.Resources
+	}
+	kept := 0
+	for _, tab := range previous.Tabs {
+		if snap.Errors[tab.SheetID+"/"+tab.TabName] != nil {
+			snap.Tabs = append(snap.Tabs, tab)
+			kept++
+		}
+	}
+	if kept == 0 {
+		return
+	}
+	sort.SliceStable(snap.Tabs, func(i, j int) bool {
+		if snap.Tabs[i].SheetID != snap.Tabs[j].SheetID {
+			return snap.Tabs[i].SheetID < snap.Tabs[j].SheetID
+		}
+		return snap.Tabs[i].TabName < snap.Tabs[j].TabName
+	})
+	log.Printf("Snapshot: kept %d tabs from the previous snapshot after errors", kept)
+}
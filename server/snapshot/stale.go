@@ -0,0 +1,161 @@
+package snapshot
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"disaster/store"
+)
+
+// staleFile records the rows marked stale and when they were marked
+const staleFile = "stale.json"
+
+// staleCheckInterval is how often RunStaleJob marks stale rows
+const staleCheckInterval = time.Hour
+
+// StaleAfter is how long a row can go unchanged before it is marked stale,
+// from STALE_AFTER_DAYS. Zero turns marking off.
+var StaleAfter = staleAfter()
+
+func staleAfter() time.Duration {
+	days := 90
+	if value := os.Getenv("STALE_AFTER_DAYS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			log.Printf("Snapshot: invalid STALE_AFTER_DAYS %q, using %d", value, days)
+		} else {
+			days = n
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StaleRow is a row that hasn't changed for StaleAfter
+type StaleRow struct {
+	SheetID   string    `json:"sheetId"`
+	TabName   string    `json:"tabName"`
+	Title     string    `json:"title"`
+	UpdatedAt time.Time `json:"updatedAt"` // when the row last changed
+	MarkedAt  time.Time `json:"markedAt"`
+}
+
+var (
+	staleMu sync.Mutex
+	stale   map[string]StaleRow
+)
+
+// loadStale reads the stale rows from the store once. It must be called with
+// staleMu held.
+func loadStale() {
+	if stale != nil {
+		return
+	}
+	stale = make(map[string]StaleRow)
+	if err := store.Load(staleFile, &stale); err != nil {
+		log.Printf("Snapshot: error loading stale rows: %v", err)
+	}
+}
+
+// RunStaleJob marks the rows of the current snapshot that are stale until
+// ctx is done
+func RunStaleJob(ctx context.Context) {
+	if StaleAfter == 0 {
+		return
+	}
+	ticker := time.NewTicker(staleCheckInterval)
+	defer ticker.Stop()
+	for {
+		MarkStale(Current(ctx), time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// MarkStale marks the rows of snap that haven't changed for StaleAfter at
+// now, and clears the marks of rows that changed or left their tab. A row
+// changes whenever any of its cells do, so it was last updated when its ID
+// was first seen.
+func MarkStale(snap *Snapshot, now time.Time) {
+	marked := make(map[string]StaleRow)
+	staleMu.Lock()
+	defer staleMu.Unlock()
+	loadStale()
+
+	for _, tab := range snap.Tabs {
+		for _, row := range tab.Rows {
+			id := tab.RowID(row)
+			updated, ok := FirstSeen(id)
+			if !ok || now.Sub(updated) < StaleAfter {
+				continue
+			}
+			if previous, ok := stale[id]; ok {
+				marked[id] = previous
+				continue
+			}
+			marked[id] = StaleRow{
+				SheetID:   tab.SheetID,
+				TabName:   tab.TabName,
+				Title:     tab.Title(row),
+				UpdatedAt: updated,
+				MarkedAt:  now,
+			}
+		}
+	}
+
+	// Tabs that couldn't be fetched keep their marks
+	for id, row := range stale {
+		if _, failed := snap.Errors[row.SheetID+"/"+row.TabName]; failed {
+			marked[id] = row
+		}
+	}
+
+	added, cleared := 0, 0
+	for id := range marked {
+		if _, ok := stale[id]; !ok {
+			added++
+		}
+	}
+	for id := range stale {
+		if _, ok := marked[id]; !ok {
+			cleared++
+		}
+	}
+	if added == 0 && cleared == 0 {
+		return
+	}
+	stale = marked
+	if err := store.Save(staleFile, stale); err != nil {
+		log.Printf("Snapshot: error saving stale rows: %v", err)
+	}
+	log.Printf("Snapshot: marked %d rows stale and cleared %d", added, cleared)
+}
+
+// Stale returns when a row was last updated if it is marked stale
+func Stale(rowID string) (time.Time, bool) {
+	staleMu.Lock()
+	defer staleMu.Unlock()
+	loadStale()
+
+	row, ok := stale[rowID]
+	return row.UpdatedAt, ok
+}
+
+// StaleRows returns every row marked stale by ID
+func StaleRows() map[string]StaleRow {
+	staleMu.Lock()
+	defer staleMu.Unlock()
+	loadStale()
+
+	rows := make(map[string]StaleRow, len(stale))
+	for id, row := range stale {
+		rows[id] = row
+	}
+	return rows
+}
@@ -0,0 +1,359 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"golang.org/x/text/language"
+
+	"disaster/apperr"
+	"disaster/components/sheet_row_cards"
+	"disaster/gdrive"
+	"disaster/geo"
+	"disaster/hours"
+)
+
+var (
+	// ErrTabNotConfigured is returned when a sheet tab has no usable entry in gdrive.SheetConfig
+	ErrTabNotConfigured = apperr.New(apperr.NotConfigured, "Tab not configured")
+	// ErrNoData is returned when a configured tab has no header row
+	ErrNoData = apperr.New(apperr.NotConfigured, "No data found")
+)
+
+// Tab holds the parsed rows of a configured sheet tab
+type Tab struct {
+	SheetID     string
+	TabName     string
+	Component   string
+	CardType    sheet_row_cards.CardType
+	Facets      []sheet_row_cards.Field
+	Rows        []any
+	FetchedAt   time.Time
+	ParseErrors int // rows skipped because they could not be parsed
+
+	// Locations places the rows of Locatable types on the map, by row ID.
+	// Rows whose address couldn't be placed are missing.
+	Locations map[string]geo.Point
+	// ServiceAreas holds the "Service Area" column by row ID, for tabs that
+	// have one. Rows with a blank service area are missing.
+	ServiceAreas map[string]geo.ServiceArea
+	// Zones holds the names in the "Zones" column by row ID, for tabs that
+	// have one. Rows not tagged with a zone are missing.
+	Zones map[string][]string
+	// Hours holds the readable opening hours in the "Hours" column by row
+	// ID, for tabs that have one
+	Hours map[string]hours.Schedule
+	// Expires holds the dates in the tab's Valid Until or Expires column by
+	// row ID, for tabs that have one. Rows with no readable date are missing.
+	Expires map[string]time.Time
+
+	// Languages are the languages the tab has translated columns for, such
+	// as "Description (es)"
+	Languages []language.Tag
+	// translations holds the rows that have translated text, with it in
+	// place of the sheet's own text, by language and row ID
+	translations map[language.Tag]map[string]any
+}
+
+// Localize returns the row with its text in the tab language best matching
+// lang, or the row itself when the tab has no translation of it. Rows are
+// identified by their untranslated text, so only localize for display.
+func (t *Tab) Localize(row any, lang language.Tag) any {
+	if len(t.Languages) == 0 {
+		return row
+	}
+	// The sheet's own columns are taken to be in English
+	available := append([]language.Tag{language.English}, t.Languages...)
+	_, index, confidence := language.NewMatcher(available).Match(lang)
+	if index == 0 || confidence == language.No {
+		return row
+	}
+	if translated, ok := t.translations[available[index]][t.RowID(row)]; ok {
+		return translated
+	}
+	return row
+}
+
+// Renderer returns the tab's card renderer showing rows in the tab language
+// best matching lang
+func (t *Tab) Renderer(lang language.Tag) sheet_row_cards.CardRenderer {
+	return func(row any) templ.Component {
+		return t.CardType.RenderFunc(t.Localize(row, lang))
+	}
+}
+
+// RowID returns the stable identifier of one of the tab's rows
+func (t *Tab) RowID(row any) string {
+	return sheet_row_cards.RowID(t.SheetID, t.TabName, row)
+}
+
+// Title returns the text of a row's Company column, or of its first column
+// when it has none
+func (t *Tab) Title(row any) string {
+	if field, ok := sheet_row_cards.FieldByCol(t.CardType.RowType, "Company"); ok {
+		return sheet_row_cards.FieldText(row, field)
+	}
+	if fields := sheet_row_cards.RowFields(t.CardType.RowType); len(fields) > 0 {
+		return sheet_row_cards.FieldText(row, fields[0])
+	}
+	return ""
+}
+
+// ServiceAreaColumn is the optional column saying where a tab's resources
+// are offered, read with geo.ParseServiceArea
+const ServiceAreaColumn = "Service Area"
+
+// ZonesColumn is the optional column naming the zones, from zones.ZoneConfig,
+// a tab's resources are for, separated by commas or semicolons
+const ZonesColumn = "Zones"
+
+// HoursColumn is the optional column giving a tab's opening hours, read
+// with hours.Parse
+const HoursColumn = "Hours"
+
+// TabSettings is the configuration of a tab in gdrive.SheetConfig
+type TabSettings struct {
+	Component string
+	DataRange string
+	CardType  sheet_row_cards.CardType
+	FacetCols []string
+}
+
+// Settings returns the configuration of a tab, or ErrTabNotConfigured when
+// the tab has no usable entry in gdrive.SheetConfig
+func Settings(sheetID, tabName string) (TabSettings, error) {
+	// Get the component config for this tab
+	tabConfig, exists := gdrive.SheetConfig[sheetID]
+	if !exists {
+		return TabSettings{}, fmt.Errorf("%w: no config found for sheet %s", ErrTabNotConfigured, sheetID)
+	}
+
+	componentConfig, ok := tabConfig[tabName].(map[string]interface{})
+	if !ok {
+		return TabSettings{}, fmt.Errorf("%w: invalid tab configuration for sheet %s, tab %s", ErrTabNotConfigured, sheetID, tabName)
+	}
+
+	// Get the component type and data range
+	componentName, ok := componentConfig["Component"].(string)
+	if !ok {
+		return TabSettings{}, fmt.Errorf("%w: no component specified in configuration for sheet %s, tab %s", ErrTabNotConfigured, sheetID, tabName)
+	}
+
+	dataRange, ok := componentConfig["StructuredDataRange"].(string)
+	if !ok {
+		return TabSettings{}, fmt.Errorf("%w: no data range specified in configuration for sheet %s, tab %s", ErrTabNotConfigured, sheetID, tabName)
+	}
+
+	// Get the card type
+	cardType, ok := sheet_row_cards.GetCardType(componentName)
+	if !ok {
+		return TabSettings{}, fmt.Errorf("%w: unknown component type %s", ErrTabNotConfigured, componentName)
+	}
+
+	facetCols, _ := componentConfig["Facets"].([]string)
+	return TabSettings{
+		Component: componentName,
+		DataRange: dataRange,
+		CardType:  cardType,
+		FacetCols: facetCols,
+	}, nil
+}
+
+// LoadTab fetches a configured tab from Google Sheets and parses its rows
+// into the row struct registered for the tab's component
+func LoadTab(ctx context.Context, sheetID, tabName string) (*Tab, error) {
+	settings, err := Settings(sheetID, tabName)
+	if err != nil {
+		return nil, err
+	}
+	componentName, dataRange, cardType := settings.Component, settings.DataRange, settings.CardType
+
+	// Get the columns rows can be filtered by
+	var facets []sheet_row_cards.Field
+	for _, col := range settings.FacetCols {
+		field, ok := sheet_row_cards.FieldByCol(cardType.RowType, col)
+		if !ok {
+			log.Printf("Warning: facet column %q is not a field of %s", col, componentName)
+			continue
+		}
+		facets = append(facets, field)
+	}
+
+	// Get the data from the sheet
+	data, err := gdrive.GetSheetData(ctx, sheetID, tabName, dataRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sheet data: %w", err)
+	}
+
+	// Get headers and rows
+	if len(data) < 1 {
+		return nil, ErrNoData
+	}
+
+	headers := data[0]
+	rows := data[1:]
+
+	log.Printf("Headers: %v", headers)
+
+	// Map column names to indices
+	colMap := make(map[string]int)
+	for j, header := range headers {
+		headerStr, ok := header.(string)
+		if !ok {
+			log.Printf("Warning: header at index %d is not a string: %v", j, header)
+			continue
+		}
+		colMap[headerStr] = j
+		log.Printf("Found column: %s at index %d", headerStr, j)
+	}
+
+	// Find the translated columns
+	translatedCols := sheet_row_cards.TranslatedColumns(colMap)
+	var languages []language.Tag
+	translations := make(map[language.Tag]map[string]any)
+	for lang := range translatedCols {
+		languages = append(languages, lang)
+		translations[lang] = make(map[string]any)
+	}
+	sort.Slice(languages, func(i, j int) bool { return languages[i].String() < languages[j].String() })
+
+	// Rows with an address are placed on the map, and rows with a service
+	// area have it read
+	var locations map[string]geo.Point
+	if sheet_row_cards.IsLocatable(cardType.RowType) {
+		locations = make(map[string]geo.Point)
+	}
+	serviceAreaCol, hasServiceAreas := colMap[ServiceAreaColumn]
+	var serviceAreas map[string]geo.ServiceArea
+	if hasServiceAreas {
+		serviceAreas = make(map[string]geo.ServiceArea)
+	}
+	hoursCol, hasHours := colMap[HoursColumn]
+	var openingHours map[string]hours.Schedule
+	if hasHours {
+		openingHours = make(map[string]hours.Schedule)
+	}
+	expiryCol, hasExpiry := -1, false
+	for _, col := range ExpiryColumns {
+		if expiryCol, hasExpiry = colMap[col]; hasExpiry {
+			break
+		}
+	}
+	var expires map[string]time.Time
+	if hasExpiry {
+		expires = make(map[string]time.Time)
+	}
+	zonesCol, hasZones := colMap[ZonesColumn]
+	var rowZones map[string][]string
+	if hasZones {
+		rowZones = make(map[string][]string)
+	}
+
+	// Parse rows into structs
+	var rowsData []any
+	parseErrors := 0
+	for i, row := range rows {
+		log.Printf("Processing row %d: %v", i, row)
+		rowData, err := sheet_row_cards.ParseRowData(cardType, row, colMap)
+		if err != nil {
+			log.Printf("Warning: error parsing row %d: %v", i, err)
+			parseErrors++
+			continue
+		}
+		rowsData = append(rowsData, rowData)
+
+		rowID := sheet_row_cards.RowID(sheetID, tabName, rowData)
+		if locatable, ok := rowData.(sheet_row_cards.Locatable); ok {
+			if point, err := geo.Default.Geocode(ctx, locatable.Address()); err == nil {
+				locations[rowID] = point
+			} else if !errors.Is(err, geo.ErrNotFound) {
+				log.Printf("Warning: error geocoding row %d: %v", i, err)
+			}
+		}
+		if hasServiceAreas && serviceAreaCol < len(row) {
+			if text := cellText(row[serviceAreaCol]); text != "" {
+				serviceAreas[rowID] = geo.ParseServiceArea(ctx, geo.Default, text)
+			}
+		}
+		if hasHours && hoursCol < len(row) {
+			if text := cellText(row[hoursCol]); text != "" {
+				if schedule, err := hours.Parse(text); err == nil {
+					openingHours[rowID] = schedule
+				} else {
+					log.Printf("Warning: can't read the hours %q of row %d", text, i)
+				}
+			}
+		}
+		if hasExpiry && expiryCol < len(row) {
+			if text := cellText(row[expiryCol]); text != "" {
+				if date, err := sheet_row_cards.ParseDate(text); err == nil {
+					expires[rowID] = date
+				} else {
+					log.Printf("Warning: can't read the expiry date %q of row %d", text, i)
+				}
+			}
+		}
+		if hasZones && zonesCol < len(row) {
+			if names := splitZones(cellText(row[zonesCol])); len(names) > 0 {
+				rowZones[rowID] = names
+			}
+		}
+		for lang, cols := range translatedCols {
+			translated, ok, err := sheet_row_cards.ParseTranslatedRow(cardType, row, colMap, cols)
+			if err != nil {
+				log.Printf("Warning: error parsing %s translation of row %d: %v", lang, i, err)
+			}
+			if ok {
+				translations[lang][rowID] = translated
+			}
+		}
+	}
+
+	return &Tab{
+		SheetID:      sheetID,
+		TabName:      tabName,
+		Component:    componentName,
+		CardType:     cardType,
+		Facets:       facets,
+		Rows:         rowsData,
+		FetchedAt:    time.Now(),
+		ParseErrors:  parseErrors,
+		Locations:    locations,
+		ServiceAreas: serviceAreas,
+		Zones:        rowZones,
+		Hours:        openingHours,
+		Expires:      expires,
+		Languages:    languages,
+		translations: translations,
+	}, nil
+}
+
+// cellText returns the text of a sheet cell, which is a string or, for cells
+// with a link, a map holding the text
+func cellText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]interface{}:
+		text, _ := v["text"].(string)
+		return strings.TrimSpace(text)
+	}
+	return ""
+}
+
+// splitZones returns the zone names in a Zones cell
+func splitZones(text string) []string {
+	var names []string
+	for _, name := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' }) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,60 @@
+// Cards the visitor saved to their plan, kept in local storage. Save buttons
+// carry the card as JSON in data-save; see SaveButton.
+const savedList = {
+    key: 'mili.savedList',
+
+    items() {
+        try {
+            return JSON.parse(localStorage.getItem(this.key)) || [];
+        } catch (err) {
+            return [];
+        }
+    },
+
+    json() {
+        return JSON.stringify(this.items());
+    },
+
+    has(rowId) {
+        return this.items().some(item => item.rowId === rowId);
+    },
+
+    store(items) {
+        localStorage.setItem(this.key, JSON.stringify(items));
+        updateSaveButtons();
+        document.body.dispatchEvent(new CustomEvent('savedListChanged'));
+    },
+
+    toggle(item) {
+        const items = this.items();
+        const kept = items.filter(saved => saved.rowId !== item.rowId);
+        this.store(kept.length === items.length ? items.concat([item]) : kept);
+    },
+};
+
+function updateSaveButtons() {
+    document.querySelectorAll('[data-save]').forEach(button => {
+        const saved = savedList.has(JSON.parse(button.dataset.save).rowId);
+        button.textContent = saved ? button.dataset.savedLabel : button.dataset.saveLabel;
+        button.setAttribute('aria-pressed', saved);
+    });
+    document.querySelectorAll('[data-saved-count]').forEach(count => {
+        count.textContent = savedList.items().length;
+    });
+}
+
+document.addEventListener('click', function(evt) {
+    const button = evt.target.closest('[data-save]');
+    if (button) {
+        savedList.toggle(JSON.parse(button.dataset.save));
+        return;
+    }
+    // A shared plan can replace the list kept on this device
+    const load = evt.target.closest('[data-load-plan]');
+    if (load) {
+        savedList.store(JSON.parse(load.dataset.loadPlan));
+        window.location.href = '/plan';
+    }
+});
+document.addEventListener('DOMContentLoaded', updateSaveButtons);
+document.addEventListener('htmx:load', updateSaveButtons);
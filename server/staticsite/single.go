@@ -0,0 +1,245 @@
+package staticsite
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// singleStyle shows one page of a single file export at a time: the targeted
+// one, or else the home page, which comes last so a targeted page before it
+// can hide it
+const singleStyle = `.export-page { display: none; }
+.export-page:target, #page-home { display: block; }
+.export-page:target ~ #page-home { display: none; }`
+
+// WriteSingle writes the pages of the site as one self-contained HTML
+// document. Each page becomes a section shown when its fragment is targeted,
+// links between pages become fragment links and local scripts, stylesheets
+// and images are inlined. Remote stylesheets are inlined too when client can
+// fetch them; remote scripts are dropped.
+func (s *Site) WriteSingle(ctx context.Context, w io.Writer, client *http.Client) error {
+	home, ok := s.byPath["/"]
+	if !ok {
+		return errors.New("the home page was not exported")
+	}
+	doc, err := home.parse()
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", home.Path, err)
+	}
+	head, body := findElement(doc, atom.Head), findElement(doc, atom.Body)
+	if head == nil || body == nil {
+		return errors.New("the home page has no head or body")
+	}
+
+	s.inlineHead(ctx, head, client)
+	head.AppendChild(textElement(atom.Style, singleStyle))
+	for body.FirstChild != nil {
+		body.RemoveChild(body.FirstChild)
+	}
+
+	ids := s.sectionIDs()
+	pages := make([]*File, 0, len(ids))
+	for _, file := range s.Files {
+		if file.IsPage() && file != home {
+			pages = append(pages, file)
+		}
+	}
+	for _, file := range append(pages, home) {
+		page, err := file.parse()
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", file.Path, err)
+		}
+		pageBody := findElement(page, atom.Body)
+		if pageBody == nil {
+			continue
+		}
+
+		// Scripts would run once per section
+		removeElements(pageBody, atom.Script)
+		for _, ref := range links(pageBody) {
+			*ref.val = s.sectionLink(ref, ids)
+		}
+
+		section := &html.Node{
+			Type:     html.ElementNode,
+			Data:     "section",
+			DataAtom: atom.Section,
+			Attr: []html.Attribute{
+				{Key: "id", Val: ids[file.Path]},
+				{Key: "class", Val: "export-page"},
+			},
+		}
+		for pageBody.FirstChild != nil {
+			c := pageBody.FirstChild
+			pageBody.RemoveChild(c)
+			section.AppendChild(c)
+		}
+		body.AppendChild(section)
+	}
+
+	return html.Render(w, doc)
+}
+
+// sectionIDs returns the element ID of each page's section, by path
+func (s *Site) sectionIDs() map[string]string {
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	for _, file := range s.Files {
+		if !file.IsPage() {
+			continue
+		}
+		id := "page-home"
+		if file.Path != "/" {
+			id = "page-" + slug(strings.TrimSuffix(file.Name(), "/index.html"))
+		}
+		unique := id
+		for n := 2; used[unique]; n++ {
+			unique = fmt.Sprintf("%s-%d", id, n)
+		}
+		used[unique] = true
+		ids[file.Path] = unique
+	}
+	return ids
+}
+
+// sectionLink rewrites a link in a page body for the single file: links to
+// pages point at their section, images are inlined and everything else
+// points at the live site
+func (s *Site) sectionLink(ref link, ids map[string]string) string {
+	target, ok := internalPath(*ref.val)
+	if !ok {
+		return *ref.val
+	}
+	if id, ok := ids[target]; ok && ref.node.DataAtom == atom.A {
+		return "#" + id
+	}
+	if file, ok := s.byPath[target]; ok && ref.node.DataAtom == atom.Img {
+		return dataURI(file.ContentType, file.Body)
+	}
+	return s.BaseURL + *ref.val
+}
+
+// inlineHead inlines the stylesheets and local scripts of head and drops its
+// remote scripts
+func (s *Site) inlineHead(ctx context.Context, head *html.Node, client *http.Client) {
+	for c := head.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case c.DataAtom == atom.Script && attr(c, "src") != "":
+			src := attr(c, "src")
+			if target, ok := internalPath(src); ok && s.byPath[target] != nil {
+				head.InsertBefore(textElement(atom.Script, string(s.byPath[target].Body)), c)
+			}
+			head.RemoveChild(c)
+		case c.DataAtom == atom.Link && attr(c, "rel") == "stylesheet":
+			href := attr(c, "href")
+			var css []byte
+			if target, ok := internalPath(href); ok && s.byPath[target] != nil {
+				css = s.byPath[target].Body
+			} else if client != nil {
+				css = fetch(ctx, client, href)
+			}
+			if css != nil {
+				head.InsertBefore(textElement(atom.Style, string(css)), c)
+				head.RemoveChild(c)
+			}
+		}
+		c = next
+	}
+}
+
+// fetch returns the body of a remote file, or nil when it can't be fetched
+func fetch(ctx context.Context, client *http.Client, rawURL string) []byte {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("Static export: not inlining %s: %v", rawURL, err)
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Static export: not inlining %s: status %d", rawURL, resp.StatusCode)
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Static export: not inlining %s: %v", rawURL, err)
+		return nil
+	}
+	return body
+}
+
+// dataURI returns a data: URL holding body
+func dataURI(contentType string, body []byte) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "application/octet-stream"
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(body)
+}
+
+// slug turns a path into an element ID fragment, e.g. "c/Food Banks" into
+// "c-Food-Banks"
+func slug(p string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range p {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// textElement returns a script or style element holding text
+func textElement(a atom.Atom, text string) *html.Node {
+	n := &html.Node{Type: html.ElementNode, Data: a.String(), DataAtom: a}
+	n.AppendChild(&html.Node{Type: html.TextNode, Data: text})
+	return n
+}
+
+// findElement returns the first element of type a in n
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// removeElements removes every element of type a from n
+func removeElements(n *html.Node, a atom.Atom) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			n.RemoveChild(c)
+		} else {
+			removeElements(c, a)
+		}
+		c = next
+	}
+}
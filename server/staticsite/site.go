@@ -0,0 +1,288 @@
+// Package staticsite renders the site to static files by crawling its handler
+// in-process, so it can be hosted on a CDN or shared offline when the server
+// is down.
+package staticsite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// skipPrefixes are paths that only work against the running server
+var skipPrefixes = []string{"/api/", "/search", "/plan", "/admin", "/subscriptions/", "/zones"}
+
+// extensions are the file extensions given to crawled paths without one,
+// by content type. Pages are written as index.html files instead.
+var extensions = map[string]string{
+	"application/rss+xml":   ".xml",
+	"application/atom+xml":  ".xml",
+	"application/xml":       ".xml",
+	"application/feed+json": ".json",
+	"application/json":      ".json",
+	"text/plain":            ".txt",
+}
+
+// File is a crawled page, feed or asset
+type File struct {
+	Path        string // escaped URL path it is served at
+	ContentType string
+	Body        []byte
+}
+
+// IsPage reports whether f is an HTML page
+func (f *File) IsPage() bool {
+	return strings.HasPrefix(f.ContentType, "text/html")
+}
+
+// parse returns the page with what needs the server removed: elements marked
+// data-export="omit" and htmx attributes
+func (f *File) parse() (*html.Node, error) {
+	doc, err := html.Parse(bytes.NewReader(f.Body))
+	if err != nil {
+		return nil, err
+	}
+	clean(doc)
+	return doc, nil
+}
+
+// Name returns the slash separated path f is written to in an exported
+// directory, e.g. "c/Food/index.html" or "feeds/new.xml"
+func (f *File) Name() string {
+	p, err := url.PathUnescape(f.Path)
+	if err != nil {
+		p = f.Path
+	}
+	p = strings.TrimPrefix(p, "/")
+
+	if f.IsPage() {
+		if p == "" || strings.HasSuffix(p, "/") {
+			return p + "index.html"
+		}
+		return p + "/index.html"
+	}
+	if path.Ext(p) == "" {
+		mediaType, _, _ := mime.ParseMediaType(f.ContentType)
+		p += extensions[mediaType]
+	}
+	return p
+}
+
+// Site is the crawled content of the site
+type Site struct {
+	// BaseURL is where the live site is served. Links to paths that were not
+	// exported, such as search, point there.
+	BaseURL string
+	Files   []*File
+
+	byPath map[string]*File
+}
+
+// Crawl requests each seed path from h, then every internal page and asset
+// the crawled pages link to. Responses other than 200 OK are logged and left
+// out of the site.
+func Crawl(ctx context.Context, h http.Handler, baseURL string, seeds []string) *Site {
+	site := &Site{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		byPath:  make(map[string]*File),
+	}
+
+	queue := append([]string(nil), seeds...)
+	seen := make(map[string]bool)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, p, nil)
+		req.Header.Set("Accept", "text/html")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			log.Printf("Static export: skipping %s: status %d", p, rec.Code)
+			continue
+		}
+
+		file := &File{
+			Path:        p,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.Body.Bytes(),
+		}
+		if file.IsPage() {
+			doc, err := file.parse()
+			if err != nil {
+				log.Printf("Static export: skipping %s: %v", p, err)
+				continue
+			}
+			for _, ref := range links(doc) {
+				if target, ok := internalPath(*ref.val); ok && exportable(target) && !seen[target] {
+					queue = append(queue, target)
+				}
+			}
+		}
+
+		site.Files = append(site.Files, file)
+		site.byPath[p] = file
+	}
+	return site
+}
+
+// WriteDir writes every file of the site under dir, with links between pages
+// made relative so the directory can be served from any path or opened from
+// disk.
+func (s *Site) WriteDir(dir string) error {
+	for _, file := range s.Files {
+		name := file.Name()
+		body := file.Body
+		if file.IsPage() {
+			doc, err := file.parse()
+			if err != nil {
+				return fmt.Errorf("parsing %s: %w", file.Path, err)
+			}
+			for _, ref := range links(doc) {
+				*ref.val = s.relativeLink(name, *ref.val)
+			}
+			var b bytes.Buffer
+			if err := html.Render(&b, doc); err != nil {
+				return fmt.Errorf("rendering %s: %w", file.Path, err)
+			}
+			body = b.Bytes()
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, body, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relativeLink rewrites an internal link in the page written to from so it
+// points at the exported file relative to that page
+func (s *Site) relativeLink(from, ref string) string {
+	target, ok := internalPath(ref)
+	if !ok {
+		return ref
+	}
+	u, _ := url.Parse(ref)
+	file, exported := s.byPath[target]
+	if !exported {
+		return s.liveLink(u)
+	}
+
+	rel := file.Name()
+	if dir := path.Dir(from); dir != "." {
+		r, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(rel))
+		if err != nil {
+			return s.liveLink(u)
+		}
+		rel = filepath.ToSlash(r)
+	}
+	return (&url.URL{Path: rel, Fragment: u.Fragment}).String()
+}
+
+// liveLink points u at the live site, or leaves it as is when there is no
+// base URL
+func (s *Site) liveLink(u *url.URL) string {
+	return s.BaseURL + u.String()
+}
+
+// clean removes elements marked data-export="omit" and htmx attributes from n
+func clean(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && attr(c, "data-export") == "omit" {
+			n.RemoveChild(c)
+		} else {
+			clean(c)
+		}
+		c = next
+	}
+
+	if n.Type != html.ElementNode {
+		return
+	}
+	kept := n.Attr[:0]
+	for _, a := range n.Attr {
+		if !strings.HasPrefix(a.Key, "hx-") {
+			kept = append(kept, a)
+		}
+	}
+	n.Attr = kept
+}
+
+// link is a URL valued attribute of an element
+type link struct {
+	node *html.Node
+	val  *string
+}
+
+// links returns the href and src attributes of anchors, link elements,
+// scripts and images in n
+func links(n *html.Node) []link {
+	var found []link
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for i := range n.Attr {
+				key := n.Attr[i].Key
+				if (key == "href" && (n.Data == "a" || n.Data == "link")) ||
+					(key == "src" && (n.Data == "script" || n.Data == "img")) {
+					found = append(found, link{node: n, val: &n.Attr[i].Val})
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// internalPath returns the escaped path a link to the site points at, without
+// its query, or false for external and fragment only links
+func internalPath(ref string) (string, bool) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	return u.EscapedPath(), true
+}
+
+// exportable reports whether the path can be served without the server
+func exportable(p string) bool {
+	for _, prefix := range skipPrefixes {
+		if strings.HasPrefix(p, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// attr returns the value of an attribute of n, or "" when it has none
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
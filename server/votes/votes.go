@@ -0,0 +1,118 @@
+// Package votes keeps visitors' anonymous confirmations that a card, such as
+// a discount code or a pickup location, worked or didn't work for them.
+package votes
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"disaster/auth"
+	"disaster/store"
+)
+
+// votesFile holds the recent votes by row ID
+const votesFile = "votes.json"
+
+const (
+	// keepFor is how long votes are kept and counted
+	keepFor = 30 * 24 * time.Hour
+	// revoteAfter is how long a voter's vote on a row stands before they can
+	// vote on it again; voting sooner replaces their vote
+	revoteAfter = 24 * time.Hour
+)
+
+// Vote is one visitor's report of whether a card worked
+type Vote struct {
+	Worked bool      `json:"worked"`
+	IP     string    `json:"ip"`     // hash of the voter's address
+	Cookie string    `json:"cookie"` // the voter's cookie ID
+	At     time.Time `json:"at"`
+}
+
+// Tally sums up the recent votes on a row
+type Tally struct {
+	Worked     int
+	Failed     int
+	LastWorked time.Time // zero when no one confirmed the row
+	LastFailed time.Time
+}
+
+var (
+	mu    sync.Mutex
+	votes map[string][]Vote
+)
+
+// load reads the votes from the store once. It must be called with mu held.
+func load() {
+	if votes != nil {
+		return
+	}
+	votes = make(map[string][]Vote)
+	if err := store.Load(votesFile, &votes); err != nil {
+		log.Printf("Votes: error loading votes: %v", err)
+	}
+}
+
+// Cast records a vote on a row from the visitor at addr with the given cookie
+// ID. A visitor matching an earlier vote on the row by address or cookie
+// within a day replaces that vote instead of adding another.
+func Cast(rowID, addr, cookie string, worked bool) error {
+	now := time.Now()
+	vote := Vote{Worked: worked, IP: addressKey(addr), Cookie: cookie, At: now}
+
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	previous := votes[rowID]
+	updated := []Vote{vote}
+	for _, v := range previous {
+		if now.Sub(v.At) > keepFor {
+			continue
+		}
+		sameVoter := v.IP == vote.IP || (cookie != "" && v.Cookie == cookie)
+		if sameVoter && now.Sub(v.At) < revoteAfter {
+			continue
+		}
+		updated = append(updated, v)
+	}
+	votes[rowID] = updated
+	if err := store.Save(votesFile, votes); err != nil {
+		votes[rowID] = previous
+		return err
+	}
+	return nil
+}
+
+// Summary tallies the recent votes on a row
+func Summary(rowID string) Tally {
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+
+	var tally Tally
+	for _, v := range votes[rowID] {
+		if time.Since(v.At) > keepFor {
+			continue
+		}
+		if v.Worked {
+			tally.Worked++
+			if v.At.After(tally.LastWorked) {
+				tally.LastWorked = v.At
+			}
+		} else {
+			tally.Failed++
+			if v.At.After(tally.LastFailed) {
+				tally.LastFailed = v.At
+			}
+		}
+	}
+	return tally
+}
+
+// addressKey identifies a voter's address so it can be matched without being
+// stored
+func addressKey(addr string) string {
+	return auth.AddressKey("votes", addr)
+}
@@ -0,0 +1,20 @@
+package zones
+
+// ZoneConfig maps GeoJSON files of zones, relative to ZONES_DIR ("zones" in
+// DATA_DIR by default), to their settings. Files are read again when they
+// change, so editors can replace a file as evacuation orders are updated.
+//
+// Each file entry supports these keys:
+//   - Kind: what the file's zones are, such as "Evacuation zone", shown with each match
+//   - NameProperty: feature property holding the zone's name, "name" by default
+//   - DescriptionProperty: optional feature property describing the zone, such as its current order
+//   - LinkProperty: optional feature property linking to the zone's official page
+//
+// For example:
+//
+//	"la-county-evacuation.geojson": map[string]interface{}{
+//		"Kind":                "Evacuation zone",
+//		"NameProperty":        "zone_name",
+//		"DescriptionProperty": "status",
+//	},
+var ZoneConfig = map[string]map[string]interface{}{}
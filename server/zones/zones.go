@@ -0,0 +1,332 @@
+// Package zones finds the evacuation, warning and other impact zones that
+// cover a place. Zones are polygons read from the GeoJSON files listed in
+// ZoneConfig.
+package zones
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jritsema/gotoolbox"
+
+	"disaster/geo"
+	"disaster/store"
+)
+
+// Dir is the directory the files in ZoneConfig are read from
+var Dir = gotoolbox.GetEnvWithDefault("ZONES_DIR", filepath.Join(store.Dir, "zones"))
+
+// Zone is a named area from a GeoJSON file, such as one evacuation zone
+type Zone struct {
+	Name        string
+	Kind        string
+	Description string
+	Link        string // http or https link to the zone's official page, if any
+
+	polygons []polygon
+}
+
+// polygon is an outer ring followed by the rings of its holes
+type polygon [][]geo.Point
+
+// Contains reports whether a point is inside the zone
+func (z Zone) Contains(p geo.Point) bool {
+	for _, poly := range z.polygons {
+		if poly.contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// milesPerDegree is the length of a degree of latitude
+const milesPerDegree = 69.09
+
+// Reaches reports whether any part of the zone is within miles of a point:
+// the zone contains the point or one of its edges comes that close
+func (z Zone) Reaches(center geo.Point, miles float64) bool {
+	if z.Contains(center) {
+		return true
+	}
+	// Near the point, longitude and latitude are scaled to miles on a plane
+	scale := math.Cos(center.Lat * math.Pi / 180)
+	plane := func(p geo.Point) (x, y float64) {
+		return (p.Lon - center.Lon) * scale * milesPerDegree, (p.Lat - center.Lat) * milesPerDegree
+	}
+	for _, poly := range z.polygons {
+		for _, ring := range poly {
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				ax, ay := plane(ring[j])
+				bx, by := plane(ring[i])
+				if distanceToOrigin(ax, ay, bx, by) <= miles {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// distanceToOrigin returns how far the segment from a to b comes from (0, 0)
+func distanceToOrigin(ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/length))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// contains reports whether a point is inside the outer ring and outside
+// every hole
+func (poly polygon) contains(p geo.Point) bool {
+	if len(poly) == 0 || !inRing(poly[0], p) {
+		return false
+	}
+	for _, hole := range poly[1:] {
+		if inRing(hole, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// inRing casts a ray from the point and counts the edges of the ring it
+// crosses, treating longitude and latitude as plane coordinates
+func inRing(ring []geo.Point, p geo.Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// FileSettings is the configuration of a file in ZoneConfig
+type FileSettings struct {
+	Kind                string
+	NameProperty        string
+	DescriptionProperty string
+	LinkProperty        string
+}
+
+// Settings returns the configuration of a file in ZoneConfig, with defaults
+// filled in
+func Settings(path string) FileSettings {
+	config := ZoneConfig[path]
+	settings := FileSettings{Kind: "Zone", NameProperty: "name"}
+	if kind, ok := config["Kind"].(string); ok && kind != "" {
+		settings.Kind = kind
+	}
+	if name, ok := config["NameProperty"].(string); ok && name != "" {
+		settings.NameProperty = name
+	}
+	settings.DescriptionProperty, _ = config["DescriptionProperty"].(string)
+	settings.LinkProperty, _ = config["LinkProperty"].(string)
+	return settings
+}
+
+// zoneFile is the zones read from a file and when the file was last changed
+type zoneFile struct {
+	modTime time.Time
+	zones   []Zone
+}
+
+var (
+	mu    sync.Mutex
+	files = make(map[string]*zoneFile)
+)
+
+// All returns the zones of every file in ZoneConfig, reading the files that
+// changed since they were last read. A file that can't be read keeps the
+// zones it last had.
+func All() []Zone {
+	paths := make([]string, 0, len(ZoneConfig))
+	for path := range ZoneConfig {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var all []Zone
+	for _, path := range paths {
+		file, err := readFile(path, files[path])
+		if err != nil {
+			log.Printf("Zones: error reading %s: %v", path, err)
+		} else {
+			files[path] = file
+		}
+		if file := files[path]; file != nil {
+			all = append(all, file.zones...)
+		}
+	}
+	return all
+}
+
+// Near returns the zones with any part within miles of a point, one per
+// kind and name
+func Near(center geo.Point, miles float64) []Zone {
+	var matches []Zone
+	seen := make(map[string]bool)
+	for _, zone := range All() {
+		key := zone.Kind + "\x00" + strings.ToLower(zone.Name)
+		if seen[key] || !zone.Reaches(center, miles) {
+			continue
+		}
+		seen[key] = true
+		matches = append(matches, zone)
+	}
+	return matches
+}
+
+// readFile returns the zones of a configured file, reusing the last read
+// when the file hasn't changed since
+func readFile(path string, last *zoneFile) (*zoneFile, error) {
+	fullPath := filepath.Join(Dir, filepath.FromSlash(path))
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil && info.ModTime().Equal(last.modTime) {
+		return last, nil
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := parseGeoJSON(data, Settings(path))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Zones: loaded %d zones from %s", len(zones), path)
+	return &zoneFile{modTime: info.ModTime(), zones: zones}, nil
+}
+
+// geoJSON is a FeatureCollection, or a single Feature
+type geoJSON struct {
+	Type       string         `json:"type"`
+	Features   []geoJSON      `json:"features"`
+	Geometry   *geometry      `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// geometry is a GeoJSON geometry. Only polygons are read.
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// parseGeoJSON reads the Polygon and MultiPolygon features of a GeoJSON
+// document as zones. Features of other geometries or without a name are
+// skipped.
+func parseGeoJSON(data []byte, settings FileSettings) ([]Zone, error) {
+	var doc geoJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	features := doc.Features
+	switch doc.Type {
+	case "FeatureCollection":
+	case "Feature":
+		features = []geoJSON{doc}
+	default:
+		return nil, fmt.Errorf("expected a FeatureCollection or Feature, got %q", doc.Type)
+	}
+
+	var zones []Zone
+	for i, feature := range features {
+		if feature.Geometry == nil {
+			continue
+		}
+		name := propertyText(feature.Properties, settings.NameProperty)
+		if name == "" {
+			log.Printf("Zones: feature %d has no %q property, skipping it", i, settings.NameProperty)
+			continue
+		}
+
+		var polygons []polygon
+		switch feature.Geometry.Type {
+		case "Polygon":
+			var coordinates [][][]float64
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &coordinates); err != nil {
+				return nil, fmt.Errorf("feature %d: invalid polygon: %w", i, err)
+			}
+			polygons = append(polygons, toPolygon(coordinates))
+		case "MultiPolygon":
+			var coordinates [][][][]float64
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &coordinates); err != nil {
+				return nil, fmt.Errorf("feature %d: invalid multipolygon: %w", i, err)
+			}
+			for _, poly := range coordinates {
+				polygons = append(polygons, toPolygon(poly))
+			}
+		default:
+			continue
+		}
+
+		zones = append(zones, Zone{
+			Name:        name,
+			Kind:        settings.Kind,
+			Description: propertyText(feature.Properties, settings.DescriptionProperty),
+			Link:        webLink(propertyText(feature.Properties, settings.LinkProperty)),
+			polygons:    polygons,
+		})
+	}
+	return zones, nil
+}
+
+// toPolygon converts GeoJSON rings of [longitude, latitude] positions
+func toPolygon(rings [][][]float64) polygon {
+	poly := make(polygon, 0, len(rings))
+	for _, ring := range rings {
+		points := make([]geo.Point, 0, len(ring))
+		for _, position := range ring {
+			if len(position) < 2 {
+				continue
+			}
+			points = append(points, geo.Point{Lat: position[1], Lon: position[0]})
+		}
+		poly = append(poly, points)
+	}
+	return poly
+}
+
+// propertyText returns a feature property as text, such as a zone name that
+// is a number
+func propertyText(properties map[string]any, key string) string {
+	if key == "" {
+		return ""
+	}
+	switch value := properties[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	default:
+		return strings.TrimSpace(fmt.Sprint(value))
+	}
+}
+
+// webLink returns link if it is an http or https URL, and "" otherwise
+func webLink(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return link
+}
@@ -0,0 +1,163 @@
+package zones
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"disaster/geo"
+)
+
+// testZones is a square zone with a hole in the middle, a diamond zone whose
+// left and right corners are level with its center, and a far away zone
+const testZones = `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "properties": {"name": "Square", "info": "Evacuate now", "url": "javascript:alert(1)"},
+      "geometry": {"type": "Polygon", "coordinates": [
+        [[-98, 30], [-97, 30], [-97, 31], [-98, 31], [-98, 30]],
+        [[-97.6, 30.4], [-97.4, 30.4], [-97.4, 30.6], [-97.6, 30.6], [-97.6, 30.4]]
+      ]}
+    },
+    {
+      "type": "Feature",
+      "properties": {"name": "Diamond", "url": "https://example.gov/diamond"},
+      "geometry": {"type": "MultiPolygon", "coordinates": [
+        [[[-95.5, 30], [-95, 30.5], [-95.5, 31], [-96, 30.5], [-95.5, 30]]],
+        [[[-80, 25], [-79, 25], [-79, 26], [-80, 25]]]
+      ]}
+    },
+    {"type": "Feature", "properties": {"name": "square"}, "geometry": {"type": "Polygon", "coordinates": [
+      [[-98, 30], [-97, 30], [-97, 31], [-98, 30]]
+    ]}},
+    {"type": "Feature", "properties": {"name": "Line"}, "geometry": {"type": "LineString", "coordinates": [[-98, 30], [-97, 31]]}},
+    {"type": "Feature", "properties": {}, "geometry": {"type": "Polygon", "coordinates": [[[-98, 30], [-97, 30], [-97, 31], [-98, 30]]]}},
+    {"type": "Feature", "properties": {"name": 42}, "geometry": {"type": "Polygon", "coordinates": [
+      [[-122, 37], [-121, 37], [-121, 38], [-122, 38], [-122, 37]]
+    ]}}
+  ]
+}`
+
+func parseTestZones(t *testing.T) map[string]Zone {
+	t.Helper()
+	zones, err := parseGeoJSON([]byte(testZones), FileSettings{Kind: "Evacuation", NameProperty: "name", DescriptionProperty: "info", LinkProperty: "url"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]Zone)
+	var names []string
+	for _, zone := range zones {
+		byName[zone.Name] = zone
+		names = append(names, zone.Name)
+	}
+	if want := []string{"Square", "Diamond", "square", "42"}; !slices.Equal(names, want) {
+		t.Fatalf("parseGeoJSON read zones %q, want %q", names, want)
+	}
+	return byName
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	zones := parseTestZones(t)
+	if zone := zones["Square"]; zone.Kind != "Evacuation" || zone.Description != "Evacuate now" || zone.Link != "" {
+		t.Errorf("Square = %+v, want kind Evacuation, its description and no link", zone)
+	}
+	if zone := zones["Diamond"]; zone.Link != "https://example.gov/diamond" || len(zone.polygons) != 2 {
+		t.Errorf("Diamond = %+v, want its link and two polygons", zone)
+	}
+	if _, err := parseGeoJSON([]byte(`{"type": "Point", "coordinates": [0, 0]}`), FileSettings{NameProperty: "name"}); err == nil {
+		t.Error("parseGeoJSON accepted a bare geometry")
+	}
+}
+
+func TestContains(t *testing.T) {
+	zones := parseTestZones(t)
+	tests := []struct {
+		name  string
+		zone  string
+		point geo.Point
+		want  bool
+	}{
+		{"inside", "Square", geo.Point{Lat: 30.2, Lon: -97.8}, true},
+		{"outside", "Square", geo.Point{Lat: 31.5, Lon: -97.5}, false},
+		{"in the hole", "Square", geo.Point{Lat: 30.5, Lon: -97.5}, false},
+		{"between the hole and the edge", "Square", geo.Point{Lat: 30.5, Lon: -97.3}, true},
+		// Points on an edge are inside on the west and south edges and
+		// outside on the east and north ones, so neighbouring zones sharing
+		// an edge don't both claim it
+		{"on the west edge", "Square", geo.Point{Lat: 30.5, Lon: -98}, true},
+		{"on the south edge", "Square", geo.Point{Lat: 30, Lon: -97.5}, true},
+		{"on the east edge", "Square", geo.Point{Lat: 30.5, Lon: -97}, false},
+		{"on the north edge", "Square", geo.Point{Lat: 31, Lon: -97.5}, false},
+		{"on the hole's west edge", "Square", geo.Point{Lat: 30.5, Lon: -97.6}, false},
+		{"on the hole's east edge", "Square", geo.Point{Lat: 30.5, Lon: -97.4}, true},
+		// A ray through a corner crosses the two edges meeting there once
+		{"level with the corners", "Diamond", geo.Point{Lat: 30.5, Lon: -95.5}, true},
+		{"west of the corners", "Diamond", geo.Point{Lat: 30.5, Lon: -96.5}, false},
+		{"in the second polygon", "Diamond", geo.Point{Lat: 25.2, Lon: -79.2}, true},
+		{"in neither polygon", "Diamond", geo.Point{Lat: 28, Lon: -88}, false},
+	}
+	for _, test := range tests {
+		if got := zones[test.zone].Contains(test.point); got != test.want {
+			t.Errorf("%s: %s.Contains(%+v) = %v, want %v", test.name, test.zone, test.point, got, test.want)
+		}
+	}
+}
+
+func TestReaches(t *testing.T) {
+	zones := parseTestZones(t)
+	tests := []struct {
+		name   string
+		center geo.Point
+		miles  float64
+		want   bool
+	}{
+		{"inside", geo.Point{Lat: 30.2, Lon: -97.8}, 0, true},
+		// A tenth of a degree of longitude is about 6 miles here
+		{"6 miles from the hole's edge", geo.Point{Lat: 30.5, Lon: -97.5}, 6.5, true},
+		{"farther from the hole's edge", geo.Point{Lat: 30.5, Lon: -97.5}, 5.5, false},
+		{"12 miles east", geo.Point{Lat: 30.5, Lon: -96.8}, 12.5, true},
+		{"farther east", geo.Point{Lat: 30.5, Lon: -96.8}, 11.5, false},
+		// The nearest point is a corner, about 14 miles to the northeast
+		{"off a corner", geo.Point{Lat: 29.9, Lon: -98.2}, 14.5, true},
+		{"farther off a corner", geo.Point{Lat: 29.9, Lon: -98.2}, 13.5, false},
+	}
+	for _, test := range tests {
+		if got := zones["Square"].Reaches(test.center, test.miles); got != test.want {
+			t.Errorf("%s: Reaches(%+v, %v) = %v, want %v", test.name, test.center, test.miles, got, test.want)
+		}
+	}
+}
+
+func TestNear(t *testing.T) {
+	savedDir, savedConfig := Dir, ZoneConfig
+	t.Cleanup(func() { Dir, ZoneConfig = savedDir, savedConfig })
+	Dir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(Dir, "evacuation.geojson"), []byte(testZones), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ZoneConfig = map[string]map[string]interface{}{"evacuation.geojson": {"Kind": "Evacuation"}}
+
+	tests := []struct {
+		center geo.Point
+		miles  float64
+		want   []string
+	}{
+		// Square and square are the same zone; the first one is kept
+		{geo.Point{Lat: 30.5, Lon: -97.2}, 1, []string{"Square"}},
+		{geo.Point{Lat: 30.5, Lon: -96.5}, 40, []string{"Square", "Diamond"}},
+		{geo.Point{Lat: 37.5, Lon: -121.5}, 0, []string{"42"}},
+		{geo.Point{Lat: 40, Lon: -100}, 10, nil},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, zone := range Near(test.center, test.miles) {
+			got = append(got, zone.Name)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Near(%+v, %v) = %q, want %q", test.center, test.miles, got, test.want)
+		}
+	}
+}